@@ -1,11 +1,19 @@
 package build
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
 	"sync"
+	"text/template"
+	"time"
 
 	"github.com/duke-git/lancet/v2/fileutil"
 )
@@ -14,12 +22,83 @@ import (
 type Platform struct {
 	OS   string
 	Arch string
+	Arm  string // GOARM variant for linux/arm, e.g. "6" or "7"
 }
 
+// String returns the platform in "os/arch" form, with "/v<GOARM>" appended for arm variants.
+func (p Platform) String() string {
+	if p.Arm != "" {
+		return fmt.Sprintf("%s/%s/v%s", p.OS, p.Arch, p.Arm)
+	}
+	return p.OS + "/" + p.Arch
+}
+
+// env returns the GOOS/GOARCH/GOARM variables for the platform.
+func (p Platform) env() []string {
+	env := []string{"GOOS=" + p.OS, "GOARCH=" + p.Arch}
+	if p.Arm != "" {
+		env = append(env, "GOARM="+p.Arm)
+	}
+	return env
+}
+
+// DefaultOutput is the default output name template, e.g. "app_linux_amd64".
+const DefaultOutput = "{{.Name}}_{{.OS}}_{{.Arch}}{{.Variant}}{{.Ext}}"
+
 type Option struct {
 	Path      string
 	ZipMode   bool
-	Platforms []Platform
+	Platforms []Platform // Platforms to build; defaults to Platforms when empty.
+
+	Dir        string            // Working directory of go build; defaults to the current directory.
+	Package    string            // Main package to build, e.g. "./cmd/foo"; defaults to ".".
+	Name       string            // Binary name used by the output template; defaults to "app".
+	Output     string            // Output name template; defaults to DefaultOutput.
+	LDFlags    string            // -ldflags template, e.g. "-s -w -X main.version={{.Version}}".
+	GCFlags    string            // -gcflags value.
+	Tags       []string          // Build tags.
+	Env        map[string]string // Extra environment variables, applied after GOOS/GOARCH.
+	CGOEnabled *bool             // Sets CGO_ENABLED when non-nil; inherited from the environment otherwise.
+	TrimPath   bool              // Passes -trimpath.
+
+	Version string // Exposed to templates as {{.Version}}.
+	Commit  string // Exposed to templates as {{.Commit}}.
+	Date    string // Exposed to templates as {{.Date}}; defaults to the build start time (RFC 3339, UTC).
+
+	Versioned      bool   // Resolves Version/Commit, injects them with -X and defaults Output to VersionedOutput.
+	VersionFile    string // Version file read when Version is empty; defaults to DefaultVersionFile.
+	VersionPackage string // Package receiving version, commit and date via -X; defaults to "main".
+
+	Archive      bool     // Packs every binary into a per-platform .tar.gz or, for windows, .zip.
+	ArchiveFiles []string // Extra files bundled into every archive, relative to Dir.
+	Checksums    bool     // Writes ChecksumsFile.
+	Manifest     bool     // Writes ManifestFile.
+
+	OnEvent func(Event) // Receives progress events; calls are serialized.
+
+	Concurrency int  // Maximum platforms built at once; defaults to runtime.NumCPU().
+	Incremental bool // Skips platforms whose sources and options are unchanged since their artifact was built.
+
+	SizeAnalysis bool             // Records per-package symbol sizes; see AnalyzeBinary.
+	SizeBaseline string           // Previous ManifestFile to compare the size analysis against.
+	SizeBudgets  map[string]int64 // Maximum binary size by Platform.String(), or "*" for every platform.
+
+	Image *ImageOptions // Writes a Docker build context and an OCI image layout of the linux binaries under ImageDir.
+
+	Toolchains map[string]Toolchain // Per-platform toolchains by Platform.String(), e.g. for CGO cross-compilation.
+}
+
+// TemplateData is the data available to the Output and LDFlags templates.
+type TemplateData struct {
+	Name    string
+	OS      string
+	Arch    string
+	Arm     string
+	Variant string // "_v<GOARM>" for arm variants, empty otherwise.
+	Ext     string // ".exe" on windows, empty otherwise.
+	Version string
+	Commit  string
+	Date    string
 }
 
 type OptionFunc func(*Option)
@@ -36,92 +115,458 @@ func WithZip(zip bool) OptionFunc {
 	}
 }
 
-func WithPlaftforms(p Platform) OptionFunc {
+// WithDir sets the working directory of go build.
+func WithDir(dir string) OptionFunc {
+	return func(o *Option) {
+		o.Dir = dir
+	}
+}
+
+// WithPackage sets the main package to build, e.g. "./cmd/foo".
+func WithPackage(pkg string) OptionFunc {
+	return func(o *Option) {
+		o.Package = pkg
+	}
+}
+
+// WithName sets the binary name used by the output template.
+func WithName(name string) OptionFunc {
+	return func(o *Option) {
+		o.Name = name
+	}
+}
+
+// WithOutput sets the output name template, see TemplateData for the fields.
+func WithOutput(tmpl string) OptionFunc {
+	return func(o *Option) {
+		o.Output = tmpl
+	}
+}
+
+// WithLDFlags sets the -ldflags template, see TemplateData for the fields.
+func WithLDFlags(ldflags string) OptionFunc {
+	return func(o *Option) {
+		o.LDFlags = ldflags
+	}
+}
+
+// WithGCFlags sets the -gcflags value.
+func WithGCFlags(gcflags string) OptionFunc {
+	return func(o *Option) {
+		o.GCFlags = gcflags
+	}
+}
+
+// WithTags adds build tags.
+func WithTags(tags ...string) OptionFunc {
+	return func(o *Option) {
+		o.Tags = append(o.Tags, tags...)
+	}
+}
+
+// WithEnv sets an extra environment variable for go build.
+func WithEnv(key, value string) OptionFunc {
+	return func(o *Option) {
+		if o.Env == nil {
+			o.Env = make(map[string]string)
+		}
+		o.Env[key] = value
+	}
+}
+
+// WithCGO sets CGO_ENABLED.
+func WithCGO(enabled bool) OptionFunc {
+	return func(o *Option) {
+		o.CGOEnabled = &enabled
+	}
+}
+
+// WithTrimPath toggles -trimpath.
+func WithTrimPath(trim bool) OptionFunc {
 	return func(o *Option) {
-		o.Platforms = append(o.Platforms, p)
+		o.TrimPath = trim
 	}
 }
 
-// Platforms contains the list of platforms to build for.
+// WithPlatforms adds platforms to build. Once any platform is added, only the
+// added platforms are built.
+func WithPlatforms(p ...Platform) OptionFunc {
+	return func(o *Option) {
+		o.Platforms = append(o.Platforms, p...)
+	}
+}
+
+// WithPlaftforms adds a platform to build.
+//
+// Deprecated: use WithPlatforms.
+func WithPlaftforms(p Platform) OptionFunc {
+	return WithPlatforms(p)
+}
+
+// Platforms contains the list of platforms built when no platform is configured.
 var Platforms = []Platform{
 	{OS: "windows", Arch: "amd64"},
 	{OS: "linux", Arch: "amd64"},
 	{OS: "darwin", Arch: "amd64"},
 }
 
-var defaultOpt = &Option{
-	Path:      "bin",
-	ZipMode:   false,
-	Platforms: Platforms,
+// AllPlatforms is the full release matrix: linux/darwin/windows × amd64/arm64/386
+// plus linux/arm with GOARM 6 and 7. darwin/386 is omitted because Go no longer
+// supports it.
+var AllPlatforms = []Platform{
+	{OS: "linux", Arch: "amd64"},
+	{OS: "linux", Arch: "arm64"},
+	{OS: "linux", Arch: "386"},
+	{OS: "linux", Arch: "arm", Arm: "6"},
+	{OS: "linux", Arch: "arm", Arm: "7"},
+	{OS: "darwin", Arch: "amd64"},
+	{OS: "darwin", Arch: "arm64"},
+	{OS: "windows", Arch: "amd64"},
+	{OS: "windows", Arch: "arm64"},
+	{OS: "windows", Arch: "386"},
+}
+
+// defaultOption returns a fresh default option so repeated Builder calls do not
+// share state.
+func defaultOption() *Option {
+	return &Option{
+		Path:    "bin",
+		ZipMode: false,
+	}
 }
 
 func Builder(opts ...OptionFunc) error {
+	option := defaultOption()
 	for _, opt := range opts {
-		opt(defaultOpt)
+		opt(option)
 	}
 
-	return Build(defaultOpt)
+	return Build(option)
 }
 
+// Build builds all platforms of option. It returns every per-platform error
+// joined; see BuildResults for the individual results.
 func Build(option *Option) error {
+	_, err := BuildResults(option)
+	return err
+}
+
+// BuildResults builds all platforms of option concurrently and returns one
+// Result per platform in platform order. The error joins every failed
+// platform; packaging only runs when all platforms succeed.
+func BuildResults(option *Option) ([]Result, error) {
+	return BuildResultsContext(context.Background(), option)
+}
+
+// BuildContext is Build with a context; cancelling ctx kills running go build
+// processes.
+func BuildContext(ctx context.Context, option *Option) error {
+	_, err := BuildResultsContext(ctx, option)
+	return err
+}
+
+// BuildResultsContext is BuildResults with a context. At most
+// Option.Concurrency platforms build at a time.
+func BuildResultsContext(ctx context.Context, option *Option) ([]Result, error) {
 	var wg sync.WaitGroup
-	errChan := make(chan error, len(option.Platforms))
 
-	if len(option.Platforms) == 0 {
-		option.Platforms = defaultOpt.Platforms
+	platforms := option.Platforms
+	if len(platforms) == 0 {
+		platforms = Platforms
 	}
 
 	if err := fileutil.CreateDir(option.Path); err != nil {
-		return fmt.Errorf("failed to create %s directory: %w", option.Path, err)
+		return nil, fmt.Errorf("failed to create %s directory: %w", option.Path, err)
+	}
+
+	b, err := newBuilder(option)
+	if err != nil {
+		return nil, err
+	}
+	if option.Incremental {
+		if err := b.loadCache(ctx); err != nil {
+			return nil, err
+		}
 	}
 
-	for _, platform := range Platforms {
+	concurrency := option.Concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+	sem := make(chan struct{}, concurrency)
+
+	results := make([]Result, len(platforms))
+	for i, platform := range platforms {
 		wg.Add(1)
-		go func(p Platform) {
+		go func(i int, p Platform) {
 			defer wg.Done()
-			if err := buildForPlatform(option.Path, p.OS, p.Arch); err != nil {
-				errChan <- fmt.Errorf("failed to build for %s/%s: %w", p.OS, p.Arch, err)
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				results[i] = Result{Platform: p, Err: ctx.Err()}
+				return
 			}
-		}(platform)
+			results[i] = b.buildForPlatform(ctx, p)
+		}(i, platform)
 	}
+	wg.Wait()
 
-	go func() {
-		wg.Wait()
-		close(errChan)
-	}()
+	if option.Incremental {
+		if err := b.saveCache(results); err != nil {
+			return results, err
+		}
+	}
 
-	for err := range errChan {
-		if err != nil {
-			return err
+	var errs []error
+	artifacts := make([]Artifact, 0, len(results))
+	for _, r := range results {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("failed to build for %s: %w", r.Platform, r.Err))
+			continue
 		}
+		artifacts = append(artifacts, Artifact{Platform: r.Platform, Binary: r.Path, BinarySize: r.Size})
+	}
+	if len(errs) > 0 {
+		return results, errors.Join(errs...)
+	}
+
+	if err := b.analyzeSizes(artifacts); err != nil {
+		return results, err
+	}
+
+	if err := b.packageArtifacts(artifacts); err != nil {
+		return results, err
 	}
 
 	if option.ZipMode {
 		if err := fileutil.Zip(option.Path, fmt.Sprintf("%s.zip", option.Path)); err != nil {
-			return fmt.Errorf("failed to zip %s: %w", option.Path, err)
+			return results, fmt.Errorf("failed to zip %s: %w", option.Path, err)
 		}
 	}
 
-	fmt.Println("All builds completed successfully.")
-	return nil
+	b.emit(Event{Type: EventPackaged})
+	return results, nil
+}
+
+// builder holds the parsed templates and resolved settings of one Build call.
+type builder struct {
+	option  *Option
+	outDir  string
+	output  *template.Template
+	ldflags *template.Template
+	version string
+	commit  string
+	date    string
+	eventMu sync.Mutex
+	cache   *buildCache // Non-nil when Incremental.
+}
+
+func newBuilder(option *Option) (*builder, error) {
+	outDir, err := filepath.Abs(option.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", option.Path, err)
+	}
+
+	outputTmpl := option.Output
+	if outputTmpl == "" {
+		outputTmpl = DefaultOutput
+		if option.Versioned {
+			outputTmpl = VersionedOutput
+		}
+	}
+	output, err := template.New("output").Option("missingkey=error").Parse(outputTmpl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output template: %w", err)
+	}
+	ldflags, err := template.New("ldflags").Option("missingkey=error").Parse(option.LDFlags)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ldflags template: %w", err)
+	}
+
+	b := &builder{
+		option:  option,
+		outDir:  outDir,
+		output:  output,
+		ldflags: ldflags,
+		version: option.Version,
+		commit:  option.Commit,
+		date:    option.Date,
+	}
+	if b.date == "" {
+		b.date = time.Now().UTC().Format(time.RFC3339)
+	}
+	if option.Versioned {
+		if b.version == "" {
+			if b.version, err = ResolveVersion(option.Dir, option.VersionFile); err != nil {
+				return nil, err
+			}
+		}
+		if b.commit == "" {
+			// Builds outside a git checkout simply carry no commit.
+			b.commit, _ = ResolveCommit(option.Dir)
+		}
+	}
+	return b, nil
+}
+
+// data returns the template data for a platform.
+func (b *builder) data(p Platform) TemplateData {
+	d := TemplateData{
+		Name:    b.option.Name,
+		OS:      p.OS,
+		Arch:    p.Arch,
+		Arm:     p.Arm,
+		Version: b.version,
+		Commit:  b.commit,
+		Date:    b.date,
+	}
+	if d.Name == "" {
+		d.Name = "app"
+	}
+	if p.Arm != "" {
+		d.Variant = "_v" + p.Arm
+	}
+	if p.OS == "windows" {
+		d.Ext = ".exe"
+	}
+	return d
+}
+
+func render(t *template.Template, data TemplateData) (string, error) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// command returns the go build command for a platform and the artifact path.
+func (b *builder) command(ctx context.Context, p Platform, data TemplateData) (*exec.Cmd, string, error) {
+	outputName, err := render(b.output, data)
+	if err != nil {
+		return nil, "", fmt.Errorf("render output name: %w", err)
+	}
+	ldflags, err := render(b.ldflags, data)
+	if err != nil {
+		return nil, "", fmt.Errorf("render ldflags: %w", err)
+	}
+	if b.option.Versioned {
+		flags, err := versionLDFlags(b.option.VersionPackage, data)
+		if err != nil {
+			return nil, "", err
+		}
+		ldflags += " " + flags
+	}
+
+	outputPath := filepath.Join(b.outDir, outputName)
+	args := []string{"build", "-o", outputPath}
+	if b.option.TrimPath {
+		args = append(args, "-trimpath")
+	}
+	if len(b.option.Tags) > 0 {
+		args = append(args, "-tags", strings.Join(b.option.Tags, ","))
+	}
+	if b.option.GCFlags != "" {
+		args = append(args, "-gcflags", b.option.GCFlags)
+	}
+	if ldflags = strings.TrimSpace(ldflags); ldflags != "" {
+		args = append(args, "-ldflags", ldflags)
+	}
+	pkg := b.option.Package
+	if pkg == "" {
+		pkg = "."
+	}
+	args = append(args, pkg)
+
+	if tc, ok := b.option.Toolchains[p.String()]; ok && tc.Image != "" {
+		cmd, err := b.containerCommand(ctx, tc, p, args, outputName)
+		return cmd, outputPath, err
+	}
+
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = b.option.Dir
+	cmd.Env = append(os.Environ(), b.env(p)...)
+	return cmd, outputPath, nil
+}
+
+// env returns the variables added to the environment of go build for a platform.
+func (b *builder) env(p Platform) []string {
+	env := p.env()
+	if b.option.CGOEnabled != nil {
+		cgo := "0"
+		if *b.option.CGOEnabled {
+			cgo = "1"
+		}
+		env = append(env, "CGO_ENABLED="+cgo)
+	}
+	keys := make([]string, 0, len(b.option.Env))
+	for k := range b.option.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		env = append(env, k+"="+b.option.Env[k])
+	}
+	return append(env, b.toolchainEnv(p)...)
 }
 
 // buildForPlatform builds the application for a specific platform.
-func buildForPlatform(path, goos, goarch string) error {
-	fmt.Printf("Starting build for %s/%s\n", goos, goarch)
-	outputName := fmt.Sprintf("app_%s_%s", goos, goarch)
-	if goos == "windows" {
-		outputName += ".exe"
+func (b *builder) buildForPlatform(ctx context.Context, p Platform) Result {
+	r := Result{Platform: p}
+	cmd, path, err := b.command(ctx, p, b.data(p))
+	if err != nil {
+		r.Err = err
+		b.emit(Event{Type: EventFinished, Platform: p, Result: &r})
+		return r
+	}
+
+	if b.cache != nil {
+		if r.Key, err = b.cacheKey(ctx, p); err != nil {
+			r.Err = err
+			b.emit(Event{Type: EventFinished, Platform: p, Result: &r})
+			return r
+		}
+		if info, err := os.Stat(path); err == nil && b.cache.get(p) == r.Key {
+			r.Path, r.Size, r.Skipped = path, info.Size(), true
+			b.emit(Event{Type: EventFinished, Platform: p, Result: &r})
+			return r
+		}
 	}
 
-	outputPath := filepath.Join(path, outputName)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	b.emit(Event{Type: EventStarted, Platform: p, Args: cmd.Args})
+
+	start := time.Now()
+	err = cmd.Run()
+	r.Duration = time.Since(start)
+	r.Output = out.String()
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = ctxErr
+		}
+		if msg := strings.TrimSpace(r.Output); msg != "" {
+			err = fmt.Errorf("%w\n%s", err, msg)
+		}
+		r.Err = fmt.Errorf("build command failed: %w", err)
+	} else if info, err := os.Stat(path); err != nil {
+		r.Err = err
+	} else {
+		r.Path, r.Size = path, info.Size()
+	}
+
+	b.emit(Event{Type: EventFinished, Platform: p, Result: &r})
+	return r
+}
 
-	cmd := exec.Command("go", "build", "-o", outputPath)
-	cmd.Env = append(os.Environ(), fmt.Sprintf("GOOS=%s", goos), fmt.Sprintf("GOARCH=%s", goarch))
-	fmt.Printf("Running build command: %v\n", cmd.Args)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("build command failed: %w", err)
+// emit delivers an event to Option.OnEvent, one call at a time.
+func (b *builder) emit(e Event) {
+	if b.option.OnEvent == nil {
+		return
 	}
-	fmt.Printf("Completed build for %s/%s\n", goos, goarch)
-	return nil
+	b.eventMu.Lock()
+	defer b.eventMu.Unlock()
+	b.option.OnEvent(e)
 }
@@ -2,7 +2,9 @@ package build
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
+	"runtime"
 	"testing"
 )
 
@@ -41,3 +43,153 @@ func TestBuilder(t *testing.T) {
 		t.Fatalf("Build function failed: %v", err)
 	}
 }
+
+func TestBuilderHonoursPlatforms(t *testing.T) {
+	host := Platform{OS: runtime.GOOS, Arch: runtime.GOARCH}
+
+	// Repeated calls must not accumulate platforms from earlier calls.
+	for i := 0; i < 2; i++ {
+		dir := t.TempDir()
+		if err := Builder(WithPath(dir), WithPlatforms(host)); err != nil {
+			t.Fatalf("Builder failed: %v", err)
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 1 {
+			t.Fatalf("expected exactly one artifact for %s, got %d", host, len(entries))
+		}
+	}
+}
+
+func TestPlatformString(t *testing.T) {
+	if got := (Platform{OS: "linux", Arch: "arm", Arm: "7"}).String(); got != "linux/arm/v7" {
+		t.Fatalf("String() = %q", got)
+	}
+}
+
+const helloSource = "package main\n\nimport \"fmt\"\n\nvar version = \"dev\"\n\nfunc main() { fmt.Print(version) }\n"
+
+// writeMainModule creates a tiny main module with src as dir/cmd/hello/main.go.
+func writeMainModule(t *testing.T, dir, src string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/hello\n\ngo 1.21\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "cmd", "hello"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "cmd", "hello", "main.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newOption returns the default options with opts applied.
+func newOption(opts ...OptionFunc) *Option {
+	option := defaultOption()
+	for _, opt := range opts {
+		opt(option)
+	}
+	return option
+}
+
+func TestBuilderInputs(t *testing.T) {
+	src := t.TempDir()
+	writeMainModule(t, src, helloSource)
+	out := t.TempDir()
+	host := Platform{OS: runtime.GOOS, Arch: runtime.GOARCH}
+
+	err := Builder(
+		WithPath(out),
+		WithPlatforms(host),
+		WithDir(src),
+		WithPackage("./cmd/hello"),
+		WithName("hello"),
+		WithOutput("{{.Name}}-{{.OS}}-{{.Arch}}{{.Ext}}"),
+		WithLDFlags("-X main.version={{.Name}}@{{.OS}}"),
+		WithTags("netgo"),
+		WithCGO(false),
+		WithTrimPath(true),
+	)
+	if err != nil {
+		t.Fatalf("Builder failed: %v", err)
+	}
+
+	ext := ""
+	if runtime.GOOS == "windows" {
+		ext = ".exe"
+	}
+	bin := filepath.Join(out, "hello-"+runtime.GOOS+"-"+runtime.GOARCH+ext)
+	got, err := exec.Command(bin).Output()
+	if err != nil {
+		t.Fatalf("run %s: %v", bin, err)
+	}
+	if want := "hello@" + runtime.GOOS; string(got) != want {
+		t.Fatalf("version = %q, want %q", got, want)
+	}
+}
+
+func TestBuilderInvalidTemplate(t *testing.T) {
+	err := Builder(WithPath(t.TempDir()), WithLDFlags("{{.Nope"))
+	if err == nil {
+		t.Fatal("expected template error")
+	}
+}
+
+func TestBuilderVersioning(t *testing.T) {
+	src := t.TempDir()
+	writeMainModule(t, src, "package main\n\nimport \"fmt\"\n\nvar version, commit, date string\n\nfunc main() { fmt.Print(version, \"|\", date) }\n")
+	if err := os.WriteFile(filepath.Join(src, "VERSION"), []byte("v1.2.3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := t.TempDir()
+
+	err := Builder(
+		WithPath(out),
+		WithPlatforms(Platform{OS: runtime.GOOS, Arch: runtime.GOARCH}),
+		WithDir(src),
+		WithPackage("./cmd/hello"),
+		WithName("hello"),
+		WithVersioning(true),
+		// Values with spaces must survive -ldflags splitting.
+		func(o *Option) { o.Date = "Tue Jan 2 03:04:05 UTC 2024" },
+	)
+	if err != nil {
+		t.Fatalf("Builder failed: %v", err)
+	}
+
+	ext := ""
+	if runtime.GOOS == "windows" {
+		ext = ".exe"
+	}
+	bin := filepath.Join(out, "hello-v1.2.3-"+runtime.GOOS+"-"+runtime.GOARCH+ext)
+	got, err := exec.Command(bin).Output()
+	if err != nil {
+		t.Fatalf("run %s: %v", bin, err)
+	}
+	if want := "v1.2.3|Tue Jan 2 03:04:05 UTC 2024"; string(got) != want {
+		t.Fatalf("output = %q, want %q", got, want)
+	}
+}
+
+func TestVersionLDFlags(t *testing.T) {
+	got, err := versionLDFlags("", TemplateData{Version: "v1", Commit: "it's", Date: "a b"})
+	if want := `-X main.version=v1 -X "main.commit=it's" -X 'main.date=a b'`; err != nil || got != want {
+		t.Fatalf("versionLDFlags = %q, %v; want %q", got, err, want)
+	}
+	if _, err := versionLDFlags("", TemplateData{Version: `"it's"`}); err == nil {
+		t.Fatal("expected an error for a value with both quotes")
+	}
+}
+
+func TestResolveVersion(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "RELEASE"), []byte(" v2.0.0 \n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	v, err := ResolveVersion(dir, "RELEASE")
+	if err != nil || v != "v2.0.0" {
+		t.Fatalf("ResolveVersion = %q, %v", v, err)
+	}
+}
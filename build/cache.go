@@ -0,0 +1,226 @@
+package build
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// CacheFile is the file in the output directory recording the build key of
+// every artifact when Option.Incremental is set.
+const CacheFile = ".build-cache.json"
+
+// WithConcurrency limits how many platforms build at once.
+func WithConcurrency(n int) OptionFunc {
+	return func(o *Option) {
+		o.Concurrency = n
+	}
+}
+
+// WithIncremental skips platforms whose artifact is up to date.
+func WithIncremental(incremental bool) OptionFunc {
+	return func(o *Option) {
+		o.Incremental = incremental
+	}
+}
+
+// buildCache maps platforms to the key their current artifact was built with.
+type buildCache struct {
+	path      string
+	goVersion string
+
+	mu   sync.Mutex
+	keys map[string]string
+}
+
+func (c *buildCache) get(p Platform) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.keys[p.String()]
+}
+
+// loadCache reads CacheFile and the go version once per build.
+func (b *builder) loadCache(ctx context.Context) error {
+	c := &buildCache{path: filepath.Join(b.outDir, CacheFile), keys: make(map[string]string)}
+	data, err := os.ReadFile(c.path)
+	switch {
+	case err == nil:
+		// A corrupt cache only costs a rebuild.
+		_ = json.Unmarshal(data, &c.keys)
+	case !errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("failed to read build cache: %w", err)
+	}
+
+	out, err := exec.CommandContext(ctx, "go", "env", "GOVERSION").Output()
+	if err != nil {
+		return fmt.Errorf("failed to get go version: %w", err)
+	}
+	c.goVersion = strings.TrimSpace(string(out))
+	b.cache = c
+	return nil
+}
+
+// saveCache records the keys of the platforms that built successfully and
+// forgets the ones that failed.
+func (b *builder) saveCache(results []Result) error {
+	c := b.cache
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, r := range results {
+		if r.Err != nil || r.Key == "" {
+			delete(c.keys, r.Platform.String())
+			continue
+		}
+		c.keys[r.Platform.String()] = r.Key
+	}
+	data, err := json.MarshalIndent(c.keys, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write build cache: %w", err)
+	}
+	return nil
+}
+
+// cacheKey hashes everything that determines the artifact of a platform: the
+// go version, the package sources, the go build arguments and the environment.
+// The default build date is left out so that it does not defeat the cache.
+func (b *builder) cacheKey(ctx context.Context, p Platform) (string, error) {
+	data := b.data(p)
+	if b.option.Date == "" {
+		data.Date = ""
+	}
+	cmd, _, err := b.command(ctx, p, data)
+	if err != nil {
+		return "", err
+	}
+
+	source, err := b.sourceHash(ctx, p)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash sources: %w", err)
+	}
+
+	h := sha256.New()
+	for _, part := range [][]string{{b.cache.goVersion, source}, stripContainerName(cmd.Args), b.env(p)} {
+		for _, s := range part {
+			io.WriteString(h, s)
+			h.Write([]byte{0})
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// goPackage is the part of "go list -json" output that determines a build.
+type goPackage struct {
+	Dir        string
+	ImportPath string
+	Standard   bool
+	Module     *struct {
+		Path    string
+		Version string
+		GoMod   string
+		Main    bool
+		Replace *struct{ Path, Version string }
+	}
+	GoFiles, CgoFiles, CFiles, CXXFiles, HFiles, SFiles, SysoFiles, EmbedFiles []string
+}
+
+// sourceHash hashes the inputs of the package of a platform as listed by
+// "go list -deps": the files of the main module and of replaced modules, the
+// versions of the other modules and go.mod and go.sum. The standard library is
+// covered by the go version. Other files under Dir, such as build outputs, do
+// not affect the hash.
+func (b *builder) sourceHash(ctx context.Context, p Platform) (string, error) {
+	pkg := b.option.Package
+	if pkg == "" {
+		pkg = "."
+	}
+	args := []string{"list", "-deps", "-json"}
+	if len(b.option.Tags) > 0 {
+		args = append(args, "-tags", strings.Join(b.option.Tags, ","))
+	}
+	cmd := exec.CommandContext(ctx, "go", append(args, pkg)...)
+	cmd.Dir = b.option.Dir
+	cmd.Env = append(os.Environ(), b.env(p)...)
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			err = fmt.Errorf("%w\n%s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("go list failed: %w", err)
+	}
+
+	h := sha256.New()
+	modFiles := make(map[string]bool)
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var pkg goPackage
+		if err := dec.Decode(&pkg); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return "", fmt.Errorf("failed to parse go list output: %w", err)
+		}
+		if pkg.Standard {
+			continue
+		}
+		io.WriteString(h, pkg.ImportPath)
+		h.Write([]byte{0})
+		if m := pkg.Module; m != nil && !m.Main && (m.Replace == nil || m.Replace.Version != "") {
+			// Module versions are immutable and verified against go.sum.
+			io.WriteString(h, m.Path+"@"+m.Version)
+			h.Write([]byte{0})
+			continue
+		}
+		if m := pkg.Module; m != nil && m.GoMod != "" {
+			modFiles[m.GoMod] = true
+		}
+		for _, files := range [][]string{pkg.GoFiles, pkg.CgoFiles, pkg.CFiles, pkg.CXXFiles, pkg.HFiles, pkg.SFiles, pkg.SysoFiles, pkg.EmbedFiles} {
+			for _, name := range files {
+				if err := hashFileInto(h, filepath.Join(pkg.Dir, name), name); err != nil {
+					return "", err
+				}
+			}
+		}
+	}
+
+	mods := make([]string, 0, len(modFiles))
+	for mod := range modFiles {
+		mods = append(mods, mod)
+	}
+	sort.Strings(mods)
+	for _, mod := range mods {
+		for _, path := range []string{mod, strings.TrimSuffix(mod, ".mod") + ".sum"} {
+			if err := hashFileInto(h, path, filepath.Base(path)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return "", err
+			}
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashFileInto writes name and the content of path to h.
+func hashFileInto(h io.Writer, path, name string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	io.WriteString(h, filepath.ToSlash(name))
+	h.Write([]byte{0})
+	_, err = io.Copy(h, f)
+	return err
+}
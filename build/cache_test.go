@@ -0,0 +1,146 @@
+package build
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestBuildIncremental(t *testing.T) {
+	src := t.TempDir()
+	writeMainModule(t, src, helloSource)
+	out := t.TempDir()
+
+	build := func(extra ...OptionFunc) Result {
+		t.Helper()
+		option := newOption(append([]OptionFunc{
+			WithPath(out),
+			WithDir(src),
+			WithPackage("./cmd/hello"),
+			WithPlatforms(Platform{OS: runtime.GOOS, Arch: runtime.GOARCH}),
+			WithIncremental(true),
+		}, extra...)...)
+		results, err := BuildResults(option)
+		if err != nil {
+			t.Fatalf("BuildResults failed: %v", err)
+		}
+		return results[0]
+	}
+
+	if r := build(); r.Skipped {
+		t.Fatal("first build must not be skipped")
+	}
+	if r := build(); !r.Skipped || r.Path == "" {
+		t.Fatalf("unchanged build was not skipped: %+v", r)
+	}
+	if r := build(WithLDFlags("-s -w")); r.Skipped {
+		t.Fatal("changed options must rebuild")
+	}
+	if err := os.WriteFile(filepath.Join(src, "cmd", "hello", "extra.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if r := build(WithLDFlags("-s -w")); r.Skipped {
+		t.Fatal("changed sources must rebuild")
+	}
+	if r := build(WithLDFlags("-s -w")); !r.Skipped {
+		t.Fatal("unchanged build was not skipped")
+	}
+}
+
+func TestBuildIncrementalZipMode(t *testing.T) {
+	src := t.TempDir()
+	writeMainModule(t, src, helloSource)
+
+	// The output directory and its zip are written inside the source tree.
+	option := func() *Option {
+		return newOption(
+			WithPath(filepath.Join(src, "dist")),
+			WithDir(src),
+			WithPackage("./cmd/hello"),
+			WithPlatforms(Platform{OS: runtime.GOOS, Arch: runtime.GOARCH}),
+			WithZip(true),
+			WithIncremental(true),
+		)
+	}
+	for i, skipped := range []bool{false, true} {
+		results, err := BuildResults(option())
+		if err != nil {
+			t.Fatalf("build %d: %v", i+1, err)
+		}
+		if results[0].Skipped != skipped {
+			t.Fatalf("build %d: Skipped = %v, want %v", i+1, results[0].Skipped, skipped)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(src, "dist.zip")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBuildCancelledWhileRunning(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiles the standard library from an empty cache")
+	}
+	src := t.TempDir()
+	writeMainModule(t, src, helloSource)
+	out := t.TempDir()
+
+	// Killed compilers may still write to the cache while the test cleans up.
+	gocache, err := os.MkdirTemp("", "build-cancel-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(gocache) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	option := newOption(
+		WithPath(out),
+		WithDir(src),
+		WithPackage("./cmd/hello"),
+		WithPlatforms(Platform{OS: runtime.GOOS, Arch: runtime.GOARCH}),
+		WithEnv("GOCACHE", gocache),
+		WithOnEvent(func(e Event) {
+			if e.Type == EventStarted {
+				time.AfterFunc(200*time.Millisecond, cancel)
+			}
+		}),
+	)
+	results, err := BuildResultsContext(ctx, option)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if r := results[0]; !errors.Is(r.Err, context.Canceled) || r.Path != "" {
+		t.Fatalf("result = %+v", r)
+	}
+	entries, err := os.ReadDir(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("partial artifacts left: %v", entries)
+	}
+}
+
+func TestBuildContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	option := newOption(
+		WithPath(t.TempDir()),
+		WithPlatforms(AllPlatforms...),
+		WithConcurrency(1),
+	)
+	results, err := BuildResultsContext(ctx, option)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	for _, r := range results {
+		if r.Err == nil {
+			t.Fatalf("%s built despite cancellation", r.Platform)
+		}
+	}
+}
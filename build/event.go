@@ -0,0 +1,41 @@
+package build
+
+import "time"
+
+// Result is the outcome of building one platform.
+type Result struct {
+	Platform Platform
+	Path     string        // Built binary; empty when the build failed.
+	Size     int64         // Size of the binary in bytes.
+	Duration time.Duration // Wall time of go build.
+	Output   string        // Combined stdout and stderr of go build.
+	Skipped  bool          // The artifact was up to date and go build did not run.
+	Key      string        // Incremental build key; empty unless Option.Incremental.
+	Err      error
+}
+
+// EventType identifies a build progress event.
+type EventType string
+
+const (
+	EventStarted    EventType = "started"     // go build is about to run for Platform.
+	EventFinished   EventType = "finished"    // go build finished for Platform; see Result.
+	EventPackaged   EventType = "packaged"    // Archives, checksums and manifest were written.
+	EventSizeReport EventType = "size_report" // Size analysis of Platform; see Sizes.
+)
+
+// Event reports build progress to Option.OnEvent.
+type Event struct {
+	Type     EventType
+	Platform Platform
+	Args     []string    // go build arguments, set for EventStarted.
+	Result   *Result     // Set for EventFinished.
+	Sizes    *SizeReport // Set for EventSizeReport.
+}
+
+// WithOnEvent sets a progress callback. Calls are serialized.
+func WithOnEvent(fn func(Event)) OptionFunc {
+	return func(o *Option) {
+		o.OnEvent = fn
+	}
+}
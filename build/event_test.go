@@ -0,0 +1,60 @@
+package build
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestBuildResults(t *testing.T) {
+	src := t.TempDir()
+	writeMainModule(t, src, helloSource)
+	host := Platform{OS: runtime.GOOS, Arch: runtime.GOARCH}
+	bogus := Platform{OS: "plan10", Arch: "amd64"}
+
+	var events []Event
+	option := newOption(
+		WithPath(t.TempDir()),
+		WithDir(src),
+		WithPackage("./cmd/hello"),
+		WithPlatforms(host, bogus),
+		WithOnEvent(func(e Event) { events = append(events, e) }),
+	)
+
+	results, err := BuildResults(option)
+	if err == nil {
+		t.Fatal("expected an error for the unsupported platform")
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("error does not wrap the go build failure: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results", len(results))
+	}
+
+	ok, failed := results[0], results[1]
+	if ok.Err != nil || ok.Path == "" || ok.Size == 0 || ok.Duration <= 0 {
+		t.Fatalf("host result = %+v", ok)
+	}
+	if failed.Err == nil || failed.Path != "" || !strings.Contains(failed.Output, "plan10") {
+		t.Fatalf("bogus result = %+v", failed)
+	}
+
+	var started, finished int
+	for _, e := range events {
+		switch e.Type {
+		case EventStarted:
+			started++
+		case EventFinished:
+			finished++
+		case EventPackaged:
+			t.Fatal("packaging must not run after a failed build")
+		}
+	}
+	if started != 2 || finished != 2 {
+		t.Fatalf("started=%d finished=%d", started, finished)
+	}
+}
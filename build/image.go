@@ -0,0 +1,400 @@
+package build
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ImageDir is the directory of the output directory that Option.Image writes
+// to. It holds the Docker build context in "context" and the OCI image layout
+// in "oci".
+const ImageDir = "image"
+
+// DefaultImageBase is the base image of generated Dockerfiles.
+const DefaultImageBase = "scratch"
+
+// ErrNoLinuxArtifacts is returned by the image writers when the build has no
+// linux binary.
+var ErrNoLinuxArtifacts = errors.New("build: no linux artifacts")
+
+// ImageOptions configures the container image of the linux binaries.
+type ImageOptions struct {
+	Repository string            // Image repository, e.g. "ghcr.io/acme/app"; defaults to the binary name.
+	Base       string            // Dockerfile base image, e.g. "gcr.io/distroless/static-debian12"; defaults to DefaultImageBase. The OCI layout is always built from scratch.
+	Tags       []string          // Defaults to the resolved version, or "latest" without one.
+	Env        []string          // "KEY=value" pairs.
+	Ports      []string          // Exposed ports, e.g. "8080/tcp".
+	Labels     map[string]string // Merged over the org.opencontainers.image version, revision and created labels.
+	User       string
+}
+
+// WithImage writes a Docker build context and an OCI image layout of the linux
+// binaries under ImageDir.
+func WithImage(opts ImageOptions) OptionFunc {
+	return func(o *Option) {
+		o.Image = &opts
+	}
+}
+
+// WriteImage writes the Docker build context of m to dir/context and its OCI
+// image layout to dir/oci.
+func WriteImage(dir string, m *Manifest, opts ImageOptions) error {
+	if err := WriteDockerContext(filepath.Join(dir, "context"), m, opts); err != nil {
+		return err
+	}
+	_, err := WriteOCILayout(filepath.Join(dir, "oci"), m, opts)
+	return err
+}
+
+// WriteDockerContext writes a multi-platform Docker build context to dir: each
+// linux binary as <platform>/<name>, e.g. "linux/arm/v7/app", and a Dockerfile
+// selecting it by TARGETPLATFORM, so that
+//
+//	docker buildx build --platform linux/amd64,linux/arm64 -t app:v1.0.0 dir
+//
+// builds every platform.
+func WriteDockerContext(dir string, m *Manifest, opts ImageOptions) error {
+	linux := linuxArtifacts(m)
+	if len(linux) == 0 {
+		return ErrNoLinuxArtifacts
+	}
+	for _, a := range linux {
+		data, err := os.ReadFile(a.Binary)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(dir, filepath.FromSlash(a.Platform.String()), m.Name)
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(dst, data, 0o755); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte(dockerfile(m, opts)), 0o644)
+}
+
+func dockerfile(m *Manifest, opts ImageOptions) string {
+	base := opts.Base
+	if base == "" {
+		base = DefaultImageBase
+	}
+	repo := imageRepository(m, opts)
+	platforms := make([]string, 0, len(m.Artifacts))
+	for _, a := range linuxArtifacts(m) {
+		platforms = append(platforms, a.Platform.String())
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Build with:\n#   docker buildx build --platform %s", strings.Join(platforms, ","))
+	for _, tag := range imageTags(m, opts) {
+		fmt.Fprintf(&b, " -t %s:%s", repo, tag)
+	}
+	fmt.Fprintf(&b, " .\nFROM %s\nARG TARGETPLATFORM\nCOPY ${TARGETPLATFORM}/%s /%s\n", base, m.Name, m.Name)
+	for _, env := range opts.Env {
+		k, v, _ := strings.Cut(env, "=")
+		fmt.Fprintf(&b, "ENV %s=%s\n", k, dockerQuote(v))
+	}
+	for _, port := range opts.Ports {
+		fmt.Fprintf(&b, "EXPOSE %s\n", port)
+	}
+	labels := imageLabels(m, opts)
+	for _, k := range sortedKeys(labels) {
+		fmt.Fprintf(&b, "LABEL %s=%s\n", k, dockerQuote(labels[k]))
+	}
+	if opts.User != "" {
+		fmt.Fprintf(&b, "USER %s\n", opts.User)
+	}
+	fmt.Fprintf(&b, "ENTRYPOINT [%q]\n", "/"+m.Name)
+	return b.String()
+}
+
+// dockerQuoter escapes the characters that are special in double-quoted
+// Dockerfile words. Unlike Go, Dockerfile has no \t or \u escapes.
+var dockerQuoter = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`)
+
+// dockerQuote quotes s as a double-quoted Dockerfile word.
+func dockerQuote(s string) string {
+	return `"` + dockerQuoter.Replace(s) + `"`
+}
+
+// OCI media types.
+const (
+	mediaTypeImageIndex    = "application/vnd.oci.image.index.v1+json"
+	mediaTypeImageManifest = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeImageConfig   = "application/vnd.oci.image.config.v1+json"
+	mediaTypeLayerGzip     = "application/vnd.oci.image.layer.v1.tar+gzip"
+	annotationRefName      = "org.opencontainers.image.ref.name"
+	annotationImageName    = "io.containerd.image.name" // Read by docker load.
+)
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Platform    *ociPlatform      `json:"platform,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type ociPlatform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+type ociIndex struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	MediaType     string          `json:"mediaType"`
+	Manifests     []ociDescriptor `json:"manifests"`
+}
+
+type ociManifest struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	MediaType     string          `json:"mediaType"`
+	Config        ociDescriptor   `json:"config"`
+	Layers        []ociDescriptor `json:"layers"`
+}
+
+type ociConfig struct {
+	Created      string `json:"created,omitempty"`
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	Variant      string `json:"variant,omitempty"`
+	Config       struct {
+		User         string              `json:"User,omitempty"`
+		Env          []string            `json:"Env,omitempty"`
+		Entrypoint   []string            `json:"Entrypoint"`
+		ExposedPorts map[string]struct{} `json:"ExposedPorts,omitempty"`
+		Labels       map[string]string   `json:"Labels,omitempty"`
+	} `json:"config"`
+	RootFS struct {
+		Type    string   `json:"type"`
+		DiffIDs []string `json:"diff_ids"`
+	} `json:"rootfs"`
+}
+
+// WriteOCILayout writes an OCI image layout of the linux binaries of m to dir
+// and returns the digest of its multi-platform image index. Every image is a
+// single layer holding the binary as /<name> on an empty base. index.json
+// references the image index once per tag, so the layout can be pushed with
+// tools like skopeo or crane, or imported with docker load once archived.
+// m.Date must be an RFC 3339 date; it sets the image creation time.
+func WriteOCILayout(dir string, m *Manifest, opts ImageOptions) (string, error) {
+	linux := linuxArtifacts(m)
+	if len(linux) == 0 {
+		return "", ErrNoLinuxArtifacts
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "blobs", "sha256"), 0o755); err != nil {
+		return "", err
+	}
+
+	created, err := time.Parse(time.RFC3339, m.Date)
+	if err != nil {
+		return "", fmt.Errorf("invalid manifest date %q: %w", m.Date, err)
+	}
+	index := ociIndex{SchemaVersion: 2, MediaType: mediaTypeImageIndex}
+	for _, a := range linux {
+		desc, err := writeOCIImage(dir, m, a, opts, created)
+		if err != nil {
+			return "", fmt.Errorf("failed to write image for %s: %w", a.Platform, err)
+		}
+		index.Manifests = append(index.Manifests, desc)
+	}
+	indexDesc, err := writeJSONBlob(dir, mediaTypeImageIndex, index)
+	if err != nil {
+		return "", err
+	}
+
+	top := ociIndex{SchemaVersion: 2, MediaType: mediaTypeImageIndex}
+	for _, tag := range imageTags(m, opts) {
+		d := indexDesc
+		d.Annotations = map[string]string{
+			annotationRefName:   tag,
+			annotationImageName: imageRepository(m, opts) + ":" + tag,
+		}
+		top.Manifests = append(top.Manifests, d)
+	}
+	if err := writeJSON(filepath.Join(dir, "index.json"), top); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "oci-layout"), []byte(`{"imageLayoutVersion":"1.0.0"}`), 0o644); err != nil {
+		return "", err
+	}
+	return indexDesc.Digest, nil
+}
+
+// writeOCIImage writes the layer, config and manifest of one platform and
+// returns the manifest descriptor.
+func writeOCIImage(dir string, m *Manifest, a Artifact, opts ImageOptions, created time.Time) (ociDescriptor, error) {
+	binary, err := os.ReadFile(a.Binary)
+	if err != nil {
+		return ociDescriptor{}, err
+	}
+
+	var layer bytes.Buffer
+	tw := tar.NewWriter(&layer)
+	hdr := &tar.Header{Name: m.Name, Mode: 0o755, Size: int64(len(binary)), ModTime: created, Typeflag: tar.TypeReg}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return ociDescriptor{}, err
+	}
+	if _, err := tw.Write(binary); err != nil {
+		return ociDescriptor{}, err
+	}
+	if err := tw.Close(); err != nil {
+		return ociDescriptor{}, err
+	}
+	diffID := digest(layer.Bytes())
+
+	var compressed bytes.Buffer
+	gw := gzip.NewWriter(&compressed)
+	if _, err := gw.Write(layer.Bytes()); err != nil {
+		return ociDescriptor{}, err
+	}
+	if err := gw.Close(); err != nil {
+		return ociDescriptor{}, err
+	}
+	layerDesc, err := writeBlob(dir, mediaTypeLayerGzip, compressed.Bytes())
+	if err != nil {
+		return ociDescriptor{}, err
+	}
+
+	platform := ociPlatformOf(a.Platform)
+	var cfg ociConfig
+	cfg.Created = created.UTC().Format(time.RFC3339)
+	cfg.Architecture, cfg.OS, cfg.Variant = platform.Architecture, platform.OS, platform.Variant
+	cfg.Config.User = opts.User
+	cfg.Config.Env = opts.Env
+	cfg.Config.Entrypoint = []string{"/" + m.Name}
+	cfg.Config.Labels = imageLabels(m, opts)
+	if len(opts.Ports) > 0 {
+		cfg.Config.ExposedPorts = make(map[string]struct{}, len(opts.Ports))
+		for _, p := range opts.Ports {
+			if !strings.Contains(p, "/") {
+				p += "/tcp"
+			}
+			cfg.Config.ExposedPorts[p] = struct{}{}
+		}
+	}
+	cfg.RootFS.Type = "layers"
+	cfg.RootFS.DiffIDs = []string{diffID}
+	configDesc, err := writeJSONBlob(dir, mediaTypeImageConfig, cfg)
+	if err != nil {
+		return ociDescriptor{}, err
+	}
+
+	manifest := ociManifest{
+		SchemaVersion: 2,
+		MediaType:     mediaTypeImageManifest,
+		Config:        configDesc,
+		Layers:        []ociDescriptor{layerDesc},
+	}
+	desc, err := writeJSONBlob(dir, mediaTypeImageManifest, manifest)
+	if err != nil {
+		return ociDescriptor{}, err
+	}
+	desc.Platform = &platform
+	return desc, nil
+}
+
+func ociPlatformOf(p Platform) ociPlatform {
+	op := ociPlatform{Architecture: p.Arch, OS: p.OS}
+	if p.Arm != "" {
+		op.Variant = "v" + p.Arm
+	}
+	return op
+}
+
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// writeBlob stores data under blobs/sha256 and returns its descriptor.
+func writeBlob(dir, mediaType string, data []byte) (ociDescriptor, error) {
+	d := digest(data)
+	path := filepath.Join(dir, "blobs", "sha256", strings.TrimPrefix(d, "sha256:"))
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return ociDescriptor{}, err
+	}
+	return ociDescriptor{MediaType: mediaType, Digest: d, Size: int64(len(data))}, nil
+}
+
+func writeJSONBlob(dir, mediaType string, v any) (ociDescriptor, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ociDescriptor{}, err
+	}
+	return writeBlob(dir, mediaType, data)
+}
+
+func writeJSON(path string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func linuxArtifacts(m *Manifest) []Artifact {
+	var out []Artifact
+	for _, a := range m.Artifacts {
+		if a.Platform.OS == "linux" {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+func imageRepository(m *Manifest, opts ImageOptions) string {
+	if opts.Repository != "" {
+		return opts.Repository
+	}
+	return m.Name
+}
+
+func imageTags(m *Manifest, opts ImageOptions) []string {
+	if len(opts.Tags) > 0 {
+		return opts.Tags
+	}
+	if m.Version != "" {
+		return []string{m.Version}
+	}
+	return []string{"latest"}
+}
+
+func imageLabels(m *Manifest, opts ImageOptions) map[string]string {
+	labels := make(map[string]string)
+	if m.Version != "" {
+		labels["org.opencontainers.image.version"] = m.Version
+	}
+	if m.Commit != "" {
+		labels["org.opencontainers.image.revision"] = m.Commit
+	}
+	if m.Date != "" {
+		labels["org.opencontainers.image.created"] = m.Date
+	}
+	for k, v := range opts.Labels {
+		labels[k] = v
+	}
+	return labels
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
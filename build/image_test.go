@@ -0,0 +1,138 @@
+package build
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readBlob(t *testing.T, dir string, d ociDescriptor, v any) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, "blobs", "sha256", strings.TrimPrefix(d.Digest, "sha256:")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digest(data) != d.Digest || int64(len(data)) != d.Size {
+		t.Fatalf("blob %s does not match its descriptor", d.Digest)
+	}
+	if v != nil {
+		if err := json.Unmarshal(data, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return data
+}
+
+func TestWriteImage(t *testing.T) {
+	bins := t.TempDir()
+	m := &Manifest{Name: "hello", Version: "v1.2.3", Commit: "abc123", Date: "2024-01-02T03:04:05Z"}
+	for _, p := range []Platform{{OS: "linux", Arch: "amd64"}, {OS: "linux", Arch: "arm", Arm: "7"}, {OS: "windows", Arch: "amd64"}} {
+		path := filepath.Join(bins, strings.ReplaceAll(p.String(), "/", "_"))
+		if err := os.WriteFile(path, []byte("binary for "+p.String()), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		m.Artifacts = append(m.Artifacts, Artifact{Platform: p, Binary: path})
+	}
+
+	dir := t.TempDir()
+	err := WriteImage(dir, m, ImageOptions{Repository: "ghcr.io/acme/hello", Ports: []string{"8080"}})
+	if err != nil {
+		t.Fatalf("WriteImage failed: %v", err)
+	}
+
+	dockerfile, err := os.ReadFile(filepath.Join(dir, "context", "Dockerfile"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"--platform linux/amd64,linux/arm/v7", "-t ghcr.io/acme/hello:v1.2.3", "FROM scratch", "COPY ${TARGETPLATFORM}/hello /hello", `ENTRYPOINT ["/hello"]`} {
+		if !strings.Contains(string(dockerfile), want) {
+			t.Errorf("Dockerfile lacks %q:\n%s", want, dockerfile)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "context", "linux", "arm", "v7", "hello")); err != nil {
+		t.Fatalf("binary not staged: %v", err)
+	}
+
+	layout := filepath.Join(dir, "oci")
+	var top ociIndex
+	data, err := os.ReadFile(filepath.Join(layout, "index.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &top); err != nil {
+		t.Fatal(err)
+	}
+	if len(top.Manifests) != 1 || top.Manifests[0].Annotations[annotationRefName] != "v1.2.3" {
+		t.Fatalf("index.json = %s", data)
+	}
+
+	var index ociIndex
+	readBlob(t, layout, top.Manifests[0], &index)
+	if len(index.Manifests) != 2 || index.Manifests[1].Platform.Variant != "v7" {
+		t.Fatalf("image index = %+v", index)
+	}
+
+	var manifest ociManifest
+	readBlob(t, layout, index.Manifests[1], &manifest)
+	var cfg ociConfig
+	readBlob(t, layout, manifest.Config, &cfg)
+	if cfg.Architecture != "arm" || cfg.Variant != "v7" || cfg.Config.Entrypoint[0] != "/hello" {
+		t.Fatalf("config = %+v", cfg)
+	}
+	if _, ok := cfg.Config.ExposedPorts["8080/tcp"]; !ok || cfg.Config.Labels["org.opencontainers.image.revision"] != "abc123" {
+		t.Fatalf("config = %+v", cfg.Config)
+	}
+
+	gz := readBlob(t, layout, manifest.Layers[0], nil)
+	zr, err := gzip.NewReader(bytes.NewReader(gz))
+	if err != nil {
+		t.Fatal(err)
+	}
+	layer, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digest(layer) != cfg.RootFS.DiffIDs[0] || !bytes.Contains(layer, []byte("binary for linux/arm/v7")) {
+		t.Fatal("layer does not match its diff id or lacks the binary")
+	}
+}
+
+func TestWriteImageWithoutLinux(t *testing.T) {
+	m := &Manifest{Name: "hello", Artifacts: []Artifact{{Platform: Platform{OS: "darwin", Arch: "arm64"}}}}
+	if err := WriteImage(t.TempDir(), m, ImageOptions{}); !errors.Is(err, ErrNoLinuxArtifacts) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestDockerfileQuoting(t *testing.T) {
+	m := &Manifest{Name: "hello"}
+	got := dockerfile(m, ImageOptions{
+		Env:    []string{"GREETING=café\tau $HOME \"lait\" C:\\tmp"},
+		Labels: map[string]string{"description": "naïve"},
+	})
+	for _, want := range []string{
+		"ENV GREETING=\"café\tau \\$HOME \\\"lait\\\" C:\\\\tmp\"\n",
+		"LABEL description=\"naïve\"\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Dockerfile lacks %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestWriteOCILayoutInvalidDate(t *testing.T) {
+	bin := filepath.Join(t.TempDir(), "hello")
+	if err := os.WriteFile(bin, []byte("binary"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	m := &Manifest{Name: "hello", Date: "yesterday", Artifacts: []Artifact{{Platform: Platform{OS: "linux", Arch: "amd64"}, Binary: bin}}}
+	if _, err := WriteOCILayout(t.TempDir(), m, ImageOptions{}); err == nil || !strings.Contains(err.Error(), "yesterday") {
+		t.Fatalf("err = %v", err)
+	}
+}
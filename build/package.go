@@ -0,0 +1,311 @@
+package build
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// ChecksumsFile is the name of the SHA-256 checksum file written to the output directory.
+	ChecksumsFile = "checksums.txt"
+	// ManifestFile is the name of the JSON manifest written to the output directory.
+	ManifestFile = "manifest.json"
+)
+
+// Artifact describes one built binary and, when archiving is enabled, its archive.
+type Artifact struct {
+	Platform Platform `json:"platform"`
+	Binary   string   `json:"binary"`
+	Archive  string   `json:"archive,omitempty"`
+	Size     int64    `json:"size"`   // Size of the released file: the archive if any, the binary otherwise.
+	SHA256   string   `json:"sha256"` // Checksum of the released file.
+
+	BinarySize int64         `json:"binary_size"`
+	Packages   []PackageSize `json:"packages,omitempty"` // Set by size analysis.
+}
+
+// File returns the released file of the artifact: the archive if any, the binary otherwise.
+func (a Artifact) File() string {
+	if a.Archive != "" {
+		return a.Archive
+	}
+	return a.Binary
+}
+
+// Manifest describes every artifact of a build. In ManifestFile the Binary and
+// Archive paths are relative to the output directory, so that the directory
+// can be moved; LoadManifest resolves them again.
+type Manifest struct {
+	Name      string     `json:"name"`
+	Version   string     `json:"version,omitempty"`
+	Commit    string     `json:"commit,omitempty"`
+	Date      string     `json:"date"`
+	Artifacts []Artifact `json:"artifacts"`
+}
+
+// WithArchive packs every binary into its own archive: .zip for windows, .tar.gz otherwise.
+func WithArchive(archive bool) OptionFunc {
+	return func(o *Option) {
+		o.Archive = archive
+	}
+}
+
+// WithArchiveFiles adds extra files such as README or LICENSE to every archive.
+// Relative paths are resolved against Dir.
+func WithArchiveFiles(files ...string) OptionFunc {
+	return func(o *Option) {
+		o.ArchiveFiles = append(o.ArchiveFiles, files...)
+	}
+}
+
+// WithChecksums writes ChecksumsFile with the SHA-256 of every released file.
+func WithChecksums(checksums bool) OptionFunc {
+	return func(o *Option) {
+		o.Checksums = checksums
+	}
+}
+
+// WithManifest writes ManifestFile describing every artifact.
+func WithManifest(manifest bool) OptionFunc {
+	return func(o *Option) {
+		o.Manifest = manifest
+	}
+}
+
+// packageArtifacts archives, checksums, describes and containerizes the built
+// binaries as configured.
+func (b *builder) packageArtifacts(artifacts []Artifact) error {
+	if b.option.Archive {
+		for i := range artifacts {
+			archive, err := b.archive(artifacts[i])
+			if err != nil {
+				return fmt.Errorf("failed to archive %s: %w", artifacts[i].Platform, err)
+			}
+			artifacts[i].Archive = archive
+		}
+	}
+
+	for i := range artifacts {
+		sum, size, err := hashFile(artifacts[i].File())
+		if err != nil {
+			return err
+		}
+		artifacts[i].SHA256, artifacts[i].Size = sum, size
+	}
+
+	if b.option.Checksums {
+		if err := writeChecksums(filepath.Join(b.outDir, ChecksumsFile), artifacts); err != nil {
+			return err
+		}
+	}
+	m := &Manifest{
+		Name:      b.data(Platform{}).Name,
+		Version:   b.version,
+		Commit:    b.commit,
+		Date:      b.date,
+		Artifacts: artifacts,
+	}
+	if b.option.Manifest {
+		rel, err := m.relativeTo(b.outDir)
+		if err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(rel, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(b.outDir, ManifestFile), append(data, '\n'), 0o644); err != nil {
+			return fmt.Errorf("failed to write manifest: %w", err)
+		}
+	}
+	if b.option.Image != nil {
+		if err := WriteImage(filepath.Join(b.outDir, ImageDir), m, *b.option.Image); err != nil {
+			return fmt.Errorf("failed to write image: %w", err)
+		}
+	}
+	return nil
+}
+
+// relativeTo returns a copy of m with the artifact paths relative to dir.
+func (m *Manifest) relativeTo(dir string) (*Manifest, error) {
+	out := *m
+	out.Artifacts = make([]Artifact, len(m.Artifacts))
+	for i, a := range m.Artifacts {
+		for _, path := range []*string{&a.Binary, &a.Archive} {
+			if *path == "" || !filepath.IsAbs(*path) {
+				continue
+			}
+			rel, err := filepath.Rel(dir, *path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to relativize %s: %w", *path, err)
+			}
+			*path = filepath.ToSlash(rel)
+		}
+		out.Artifacts[i] = a
+	}
+	return &out, nil
+}
+
+// archive packs the artifact binary and the extra files and returns the archive path.
+// The binary is stored as Name plus the platform extension.
+func (b *builder) archive(a Artifact) (string, error) {
+	data := b.data(a.Platform)
+	base := strings.TrimSuffix(a.Binary, data.Ext)
+
+	files := []archiveEntry{{name: data.Name + data.Ext, path: a.Binary, mode: 0o755}}
+	for _, f := range b.option.ArchiveFiles {
+		path := f
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(b.option.Dir, path)
+		}
+		files = append(files, archiveEntry{name: filepath.Base(f), path: path, mode: 0o644})
+	}
+
+	if a.Platform.OS == "windows" {
+		return base + ".zip", writeZip(base+".zip", files)
+	}
+	return base + ".tar.gz", writeTarGz(base+".tar.gz", files)
+}
+
+type archiveEntry struct {
+	name string
+	path string
+	mode os.FileMode
+}
+
+func writeTarGz(dst string, entries []archiveEntry) (err error) {
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	for _, e := range entries {
+		if err := addTar(tw, e); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+func addTar(tw *tar.Writer, e archiveEntry) error {
+	src, err := os.Open(e.path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	hdr := &tar.Header{
+		Name:    e.name,
+		Mode:    int64(e.mode),
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, src)
+	return err
+}
+
+func writeZip(dst string, entries []archiveEntry) (err error) {
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	zw := zip.NewWriter(f)
+	for _, e := range entries {
+		if err := addZip(zw, e); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func addZip(zw *zip.Writer, e archiveEntry) error {
+	src, err := os.Open(e.path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	hdr, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	hdr.Name = e.name
+	hdr.Method = zip.Deflate
+	hdr.SetMode(e.mode)
+	w, err := zw.CreateHeader(hdr)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, src)
+	return err
+}
+
+// hashFile returns the hex SHA-256 and the size of a file.
+func hashFile(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+// writeChecksums writes "<sha256>  <file>" lines sorted by file name, the
+// format understood by sha256sum -c.
+func writeChecksums(dst string, artifacts []Artifact) error {
+	sorted := append([]Artifact(nil), artifacts...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return filepath.Base(sorted[i].File()) < filepath.Base(sorted[j].File())
+	})
+	lines := make([]string, 0, len(sorted))
+	for _, a := range sorted {
+		lines = append(lines, a.SHA256+"  "+filepath.Base(a.File()))
+	}
+	if err := os.WriteFile(dst, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		return fmt.Errorf("failed to write checksums: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package build
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestBuilderPackaging(t *testing.T) {
+	src := t.TempDir()
+	writeMainModule(t, src, helloSource)
+	if err := os.WriteFile(filepath.Join(src, "README.md"), []byte("# hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := t.TempDir()
+	host := Platform{OS: runtime.GOOS, Arch: runtime.GOARCH}
+
+	err := Builder(
+		WithPath(out),
+		WithPlatforms(host),
+		WithDir(src),
+		WithPackage("./cmd/hello"),
+		WithName("hello"),
+		WithVersion("v1.0.0"),
+		WithVersioning(true),
+		WithArchive(true),
+		WithArchiveFiles("README.md"),
+		WithChecksums(true),
+		WithManifest(true),
+	)
+	if err != nil {
+		t.Fatalf("Builder failed: %v", err)
+	}
+
+	base := filepath.Join(out, "hello-v1.0.0-"+runtime.GOOS+"-"+runtime.GOARCH)
+	var archive string
+	var names []string
+	if runtime.GOOS == "windows" {
+		archive = base + ".zip"
+		names = zipNames(t, archive)
+	} else {
+		archive = base + ".tar.gz"
+		names = tarNames(t, archive)
+	}
+	sort.Strings(names)
+	want := []string{"README.md", "hello"}
+	if runtime.GOOS == "windows" {
+		want[1] = "hello.exe"
+	}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Fatalf("archive entries = %v, want %v", names, want)
+	}
+
+	sum, _, err := hashFile(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checksums, err := os.ReadFile(filepath.Join(out, ChecksumsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line := sum + "  " + filepath.Base(archive) + "\n"; string(checksums) != line {
+		t.Fatalf("checksums = %q, want %q", checksums, line)
+	}
+
+	data, err := os.ReadFile(filepath.Join(out, ManifestFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Name != "hello" || m.Version != "v1.0.0" || len(m.Artifacts) != 1 {
+		t.Fatalf("manifest = %+v", m)
+	}
+	// Paths are stored relative to the output directory.
+	if a := m.Artifacts[0]; a.Platform != host || a.Archive != filepath.Base(archive) || a.SHA256 != sum {
+		t.Fatalf("artifact = %+v", a)
+	}
+
+	moved := filepath.Join(t.TempDir(), "dist")
+	if err := os.Rename(out, moved); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadManifest(filepath.Join(moved, ManifestFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := loaded.Artifacts[0]; a.Archive != filepath.Join(moved, filepath.Base(archive)) {
+		t.Fatalf("loaded artifact = %+v", a)
+	}
+	if _, err := os.Stat(loaded.Artifacts[0].Binary); err != nil {
+		t.Fatalf("binary not resolved: %v", err)
+	}
+}
+
+func tarNames(t *testing.T, path string) []string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gr)
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		names = append(names, hdr.Name)
+	}
+	return names
+}
+
+func zipNames(t *testing.T, path string) []string {
+	t.Helper()
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+func TestWriteZip(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "app.exe")
+	if err := os.WriteFile(src, []byte("MZ"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(dir, "app.zip")
+	if err := writeZip(dst, []archiveEntry{{name: "app.exe", path: src, mode: 0o755}}); err != nil {
+		t.Fatal(err)
+	}
+	if names := zipNames(t, dst); len(names) != 1 || names[0] != "app.exe" {
+		t.Fatalf("zip entries = %v", names)
+	}
+}
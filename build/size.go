@@ -0,0 +1,342 @@
+package build
+
+import (
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrNoSymbols is returned by AnalyzeBinary for binaries without a symbol
+	// table, e.g. those linked with -s.
+	ErrNoSymbols = errors.New("build: binary has no symbol table")
+	// ErrSizeBudget is returned when a binary exceeds its size budget.
+	ErrSizeBudget = errors.New("build: size budget exceeded")
+)
+
+// PackageSize is the total size of the symbols of one package in a binary.
+// Symbols without a package are grouped by their prefix, e.g. "type:" or "go:".
+type PackageSize struct {
+	Package string `json:"package"`
+	Size    int64  `json:"size"`
+}
+
+// SizeChange is the difference of a package between two builds of a platform.
+// Package is empty for the change of the whole binary.
+type SizeChange struct {
+	Platform Platform
+	Package  string
+	Old      int64
+	New      int64
+}
+
+// Delta returns New - Old.
+func (c SizeChange) Delta() int64 {
+	return c.New - c.Old
+}
+
+// SizeReport is the size analysis of one built binary.
+type SizeReport struct {
+	Platform Platform
+	Size     int64         // Binary size in bytes.
+	Budget   int64         // Configured budget; zero when none.
+	Packages []PackageSize // Largest first; empty without a symbol table.
+	Changes  []SizeChange  // Against Option.SizeBaseline; empty without a baseline.
+}
+
+// WithSizeAnalysis records per-package symbol sizes in the manifest and reports
+// them with EventSizeReport.
+func WithSizeAnalysis(analysis bool) OptionFunc {
+	return func(o *Option) {
+		o.SizeAnalysis = analysis
+	}
+}
+
+// WithSizeBaseline compares the size analysis against a previous ManifestFile.
+func WithSizeBaseline(manifest string) OptionFunc {
+	return func(o *Option) {
+		o.SizeBaseline = manifest
+	}
+}
+
+// WithSizeBudget fails the build when the binary of platform, given in
+// Platform.String form or "*" for every platform, is larger than limit bytes.
+func WithSizeBudget(platform string, limit int64) OptionFunc {
+	return func(o *Option) {
+		if o.SizeBudgets == nil {
+			o.SizeBudgets = make(map[string]int64)
+		}
+		o.SizeBudgets[platform] = limit
+	}
+}
+
+// LoadManifest reads a ManifestFile and resolves the relative artifact paths
+// against its directory.
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+	dir := filepath.Dir(path)
+	for i := range m.Artifacts {
+		a := &m.Artifacts[i]
+		for _, p := range []*string{&a.Binary, &a.Archive} {
+			if *p != "" && !filepath.IsAbs(*p) {
+				*p = filepath.Join(dir, filepath.FromSlash(*p))
+			}
+		}
+	}
+	return &m, nil
+}
+
+// CompareSizes returns the binary and package size changes of the platforms
+// present in both prev and cur, platforms in cur order and packages by
+// decreasing absolute delta. Unchanged packages are omitted.
+func CompareSizes(prev, cur []Artifact) []SizeChange {
+	old := make(map[Platform]Artifact, len(prev))
+	for _, a := range prev {
+		old[a.Platform] = a
+	}
+
+	var changes []SizeChange
+	for _, a := range cur {
+		o, ok := old[a.Platform]
+		if !ok {
+			continue
+		}
+		if o.BinarySize != a.BinarySize {
+			changes = append(changes, SizeChange{Platform: a.Platform, Old: o.BinarySize, New: a.BinarySize})
+		}
+
+		sizes := make(map[string][2]int64)
+		for _, p := range o.Packages {
+			s := sizes[p.Package]
+			s[0] = p.Size
+			sizes[p.Package] = s
+		}
+		for _, p := range a.Packages {
+			s := sizes[p.Package]
+			s[1] = p.Size
+			sizes[p.Package] = s
+		}
+		var pkgs []SizeChange
+		for pkg, s := range sizes {
+			if s[0] != s[1] {
+				pkgs = append(pkgs, SizeChange{Platform: a.Platform, Package: pkg, Old: s[0], New: s[1]})
+			}
+		}
+		sort.Slice(pkgs, func(i, j int) bool {
+			di, dj := abs(pkgs[i].Delta()), abs(pkgs[j].Delta())
+			if di != dj {
+				return di > dj
+			}
+			return pkgs[i].Package < pkgs[j].Package
+		})
+		changes = append(changes, pkgs...)
+	}
+	return changes
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// analyzeSizes fills in the package sizes of the artifacts, reports them and
+// enforces the size budgets.
+func (b *builder) analyzeSizes(artifacts []Artifact) error {
+	if !b.option.SizeAnalysis && len(b.option.SizeBudgets) == 0 {
+		return nil
+	}
+
+	var baseline []Artifact
+	if b.option.SizeAnalysis && b.option.SizeBaseline != "" {
+		m, err := LoadManifest(b.option.SizeBaseline)
+		if err != nil {
+			return err
+		}
+		baseline = m.Artifacts
+	}
+
+	var errs []error
+	for i := range artifacts {
+		a := &artifacts[i]
+		report := SizeReport{Platform: a.Platform, Size: a.BinarySize, Budget: b.budget(a.Platform)}
+		if b.option.SizeAnalysis {
+			pkgs, err := AnalyzeBinary(a.Binary)
+			if err != nil && !errors.Is(err, ErrNoSymbols) {
+				return fmt.Errorf("failed to analyze %s: %w", a.Platform, err)
+			}
+			a.Packages = pkgs
+			report.Packages = pkgs
+			report.Changes = CompareSizes(baseline, artifacts[i:i+1])
+			b.emit(Event{Type: EventSizeReport, Platform: a.Platform, Sizes: &report})
+		}
+		if report.Budget > 0 && report.Size > report.Budget {
+			errs = append(errs, fmt.Errorf("%w: %s is %d bytes, budget %d", ErrSizeBudget, a.Platform, report.Size, report.Budget))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (b *builder) budget(p Platform) int64 {
+	if limit, ok := b.option.SizeBudgets[p.String()]; ok {
+		return limit
+	}
+	return b.option.SizeBudgets["*"]
+}
+
+// AnalyzeBinary returns the per-package symbol sizes of an ELF, Mach-O or PE
+// binary, largest first. Symbols without a size are sized up to the next
+// symbol of their section.
+func AnalyzeBinary(path string) ([]PackageSize, error) {
+	syms, err := readSymbols(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(syms) == 0 {
+		return nil, ErrNoSymbols
+	}
+
+	sort.Slice(syms, func(i, j int) bool {
+		if syms[i].sect != syms[j].sect {
+			return syms[i].sect < syms[j].sect
+		}
+		return syms[i].addr < syms[j].addr
+	})
+	totals := make(map[string]int64)
+	for i, s := range syms {
+		size := s.size
+		if size == 0 {
+			end := s.sectEnd
+			if i+1 < len(syms) && syms[i+1].sect == s.sect && syms[i+1].addr < end {
+				end = syms[i+1].addr
+			}
+			if end > s.addr {
+				size = end - s.addr
+			}
+		}
+		totals[symbolPackage(s.name)] += int64(size)
+	}
+
+	pkgs := make([]PackageSize, 0, len(totals))
+	for pkg, size := range totals {
+		pkgs = append(pkgs, PackageSize{Package: pkg, Size: size})
+	}
+	sort.Slice(pkgs, func(i, j int) bool {
+		if pkgs[i].Size != pkgs[j].Size {
+			return pkgs[i].Size > pkgs[j].Size
+		}
+		return pkgs[i].Package < pkgs[j].Package
+	})
+	return pkgs, nil
+}
+
+// symbolPackage returns the package of a Go symbol name such as
+// "github.com/a/b.(*T).M" or "main.main[...]".
+func symbolPackage(name string) string {
+	for _, prefix := range []string{"type:", "go:"} {
+		if strings.HasPrefix(name, prefix) {
+			return prefix
+		}
+	}
+	if i := strings.IndexByte(name, '['); i >= 0 {
+		name = name[:i]
+	}
+	slash := strings.LastIndexByte(name, '/')
+	if dot := strings.IndexByte(name[slash+1:], '.'); dot >= 0 {
+		return name[:slash+1+dot]
+	}
+	return name
+}
+
+type symbol struct {
+	name    string
+	sect    int
+	addr    uint64
+	size    uint64
+	sectEnd uint64
+}
+
+func readSymbols(path string) ([]symbol, error) {
+	if f, err := elf.Open(path); err == nil {
+		defer f.Close()
+		return elfSymbols(f)
+	}
+	if f, err := macho.Open(path); err == nil {
+		defer f.Close()
+		return machoSymbols(f), nil
+	}
+	if f, err := pe.Open(path); err == nil {
+		defer f.Close()
+		return peSymbols(f), nil
+	}
+	return nil, fmt.Errorf("%s: unknown binary format", path)
+}
+
+func elfSymbols(f *elf.File) ([]symbol, error) {
+	syms, err := f.Symbols()
+	if errors.Is(err, elf.ErrNoSymbols) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var out []symbol
+	for _, s := range syms {
+		typ := elf.ST_TYPE(s.Info)
+		if typ != elf.STT_FUNC && typ != elf.STT_OBJECT {
+			continue
+		}
+		if s.Section == elf.SHN_UNDEF || int(s.Section) >= len(f.Sections) {
+			continue
+		}
+		sect := f.Sections[s.Section]
+		out = append(out, symbol{name: s.Name, sect: int(s.Section), addr: s.Value, size: s.Size, sectEnd: sect.Addr + sect.Size})
+	}
+	return out, nil
+}
+
+func machoSymbols(f *macho.File) []symbol {
+	if f.Symtab == nil {
+		return nil
+	}
+	const stabTypeMask = 0xe0
+	var out []symbol
+	for _, s := range f.Symtab.Syms {
+		if s.Type&stabTypeMask != 0 || s.Sect == 0 || int(s.Sect) > len(f.Sections) {
+			continue
+		}
+		sect := f.Sections[s.Sect-1]
+		// Some symbols carry the C-style leading underscore.
+		name := strings.TrimPrefix(s.Name, "_")
+		out = append(out, symbol{name: name, sect: int(s.Sect), addr: s.Value, sectEnd: sect.Addr + sect.Size})
+	}
+	return out
+}
+
+func peSymbols(f *pe.File) []symbol {
+	var out []symbol
+	for _, s := range f.Symbols {
+		if s.SectionNumber <= 0 || int(s.SectionNumber) > len(f.Sections) {
+			continue
+		}
+		// Values are offsets into the section.
+		sect := f.Sections[s.SectionNumber-1]
+		out = append(out, symbol{name: s.Name, sect: int(s.SectionNumber), addr: uint64(s.Value), sectEnd: uint64(sect.VirtualSize)})
+	}
+	return out
+}
@@ -0,0 +1,103 @@
+package build
+
+import (
+	"errors"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestSizeAnalysisAndBudget(t *testing.T) {
+	src := t.TempDir()
+	writeMainModule(t, src, helloSource)
+	out := t.TempDir()
+	host := Platform{OS: runtime.GOOS, Arch: runtime.GOARCH}
+
+	var report *SizeReport
+	err := Builder(
+		WithPath(out),
+		WithDir(src),
+		WithPackage("./cmd/hello"),
+		WithPlatforms(host),
+		WithSizeAnalysis(true),
+		WithManifest(true),
+		WithOnEvent(func(e Event) {
+			if e.Type == EventSizeReport {
+				report = e.Sizes
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Builder failed: %v", err)
+	}
+	if report == nil || report.Size == 0 {
+		t.Fatalf("report = %+v", report)
+	}
+	found := make(map[string]bool)
+	for _, p := range report.Packages {
+		found[p.Package] = p.Size > 0
+	}
+	if !found["runtime"] || !found["main"] {
+		t.Fatalf("packages = %+v", report.Packages)
+	}
+
+	m, err := LoadManifest(filepath.Join(out, ManifestFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Artifacts) != 1 || len(m.Artifacts[0].Packages) == 0 {
+		t.Fatalf("manifest has no size analysis: %+v", m)
+	}
+
+	err = Builder(
+		WithPath(t.TempDir()),
+		WithDir(src),
+		WithPackage("./cmd/hello"),
+		WithPlatforms(host),
+		WithSizeAnalysis(true),
+		WithSizeBaseline(filepath.Join(out, ManifestFile)),
+		WithSizeBudget("*", 1024),
+	)
+	if !errors.Is(err, ErrSizeBudget) {
+		t.Fatalf("err = %v, want ErrSizeBudget", err)
+	}
+}
+
+func TestCompareSizes(t *testing.T) {
+	p := Platform{OS: "linux", Arch: "amd64"}
+	prev := []Artifact{{Platform: p, BinarySize: 100, Packages: []PackageSize{{"main", 10}, {"fmt", 40}, {"gone", 5}}}}
+	cur := []Artifact{{Platform: p, BinarySize: 130, Packages: []PackageSize{{"main", 10}, {"fmt", 60}, {"new", 15}}}}
+
+	got := CompareSizes(prev, cur)
+	want := []SizeChange{
+		{Platform: p, Old: 100, New: 130},
+		{Platform: p, Package: "fmt", Old: 40, New: 60},
+		{Platform: p, Package: "new", Old: 0, New: 15},
+		{Platform: p, Package: "gone", Old: 5, New: 0},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("CompareSizes = %+v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("change %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSymbolPackage(t *testing.T) {
+	for name, want := range map[string]string{
+		"main.main":                          "main",
+		"runtime.mallocgc":                   "runtime",
+		"github.com/a/b.(*T).M":              "github.com/a/b",
+		"github.com/a/b.F[go.shape.*uint8]":  "github.com/a/b",
+		"type:*github.com/a/b.T":             "type:",
+		"go:buildid":                         "go:",
+		"internal/abi.(*Type).Kind":          "internal/abi",
+		"vendor/golang.org/x/net/dns.Parser": "vendor/golang.org/x/net/dns",
+	} {
+		if got := symbolPackage(name); got != want {
+			t.Errorf("symbolPackage(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
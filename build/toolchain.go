@@ -0,0 +1,158 @@
+package build
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Toolchain configures how a platform is built, typically to cross-compile
+// with CGO, e.g. for linux/arm64:
+//
+//	Toolchain{CC: "aarch64-linux-gnu-gcc", CXX: "aarch64-linux-gnu-g++", Sysroot: "/usr/aarch64-linux-gnu"}
+//
+// or, to build inside a cross-compilation image instead of on the host:
+//
+//	Toolchain{Image: "ghcr.io/goreleaser/goreleaser-cross:v1.24", CC: "aarch64-linux-gnu-gcc"}
+type Toolchain struct {
+	CC      string            // C compiler; enables CGO unless Option.CGOEnabled is set.
+	CXX     string            // C++ compiler.
+	Sysroot string            // Passed as --sysroot in CGO_CFLAGS, CGO_CXXFLAGS and CGO_LDFLAGS.
+	Env     map[string]string // Extra environment variables, applied after Option.Env.
+
+	// Image, when set, runs go build in a container of this image. Dir is
+	// mounted at /src and the output directory at /out; the host environment
+	// is not passed through. GOPATH, GOCACHE, GOMODCACHE, GOROOT and GOTMPDIR
+	// are host paths and are only taken from Env, not Option.Env; set them
+	// along with RunArgs mounts to share a cache. The incremental cache keys
+	// on the reference, not the image content: pin a digest
+	// (image@sha256:...) rather than a moving tag to rebuild when the image
+	// changes.
+	Image   string
+	Runtime string   // Container runtime; defaults to "docker".
+	RunArgs []string // Extra arguments for "<runtime> run", e.g. volume mounts for a module cache.
+}
+
+// WithToolchain sets the toolchain of a platform, given in Platform.String form.
+func WithToolchain(platform string, tc Toolchain) OptionFunc {
+	return func(o *Option) {
+		if o.Toolchains == nil {
+			o.Toolchains = make(map[string]Toolchain)
+		}
+		o.Toolchains[platform] = tc
+	}
+}
+
+// toolchainEnv returns the environment variables of the toolchain of a platform.
+func (b *builder) toolchainEnv(p Platform) []string {
+	tc, ok := b.option.Toolchains[p.String()]
+	if !ok {
+		return nil
+	}
+
+	var env []string
+	if tc.CC != "" {
+		if b.option.CGOEnabled == nil {
+			env = append(env, "CGO_ENABLED=1")
+		}
+		env = append(env, "CC="+tc.CC)
+	}
+	if tc.CXX != "" {
+		env = append(env, "CXX="+tc.CXX)
+	}
+	if tc.Sysroot != "" {
+		flag := "--sysroot=" + tc.Sysroot
+		env = append(env, "CGO_CFLAGS="+flag, "CGO_CXXFLAGS="+flag, "CGO_LDFLAGS="+flag)
+	}
+
+	keys := make([]string, 0, len(tc.Env))
+	for k := range tc.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		env = append(env, k+"="+tc.Env[k])
+	}
+	return env
+}
+
+// hostPathEnv are the go variables holding host paths, which are not mounted
+// in toolchain containers.
+var hostPathEnv = map[string]bool{
+	"GOPATH":     true,
+	"GOCACHE":    true,
+	"GOMODCACHE": true,
+	"GOROOT":     true,
+	"GOTMPDIR":   true,
+}
+
+// containerCommand wraps go build args in "<runtime> run" for the toolchain
+// image. outputName is relative to the output directory.
+func (b *builder) containerCommand(ctx context.Context, tc Toolchain, p Platform, args []string, outputName string) (*exec.Cmd, error) {
+	src := b.option.Dir
+	if src == "" {
+		src = "."
+	}
+	src, err := filepath.Abs(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", b.option.Dir, err)
+	}
+
+	engine := tc.Runtime
+	if engine == "" {
+		engine = "docker"
+	}
+	// A cancelled client does not stop the container; name it to kill it.
+	name := fmt.Sprintf("go-build-%s-%s-%s", p.OS, p.Arch, strings.ToLower(rand.Text()))
+	run := []string{"run", "--rm", "--name", name,
+		"-v", src + ":/src",
+		"-v", b.outDir + ":/out",
+		"-w", "/src",
+		// The container user may have no writable home.
+		"-e", "HOME=/tmp",
+		"-e", "GOCACHE=/tmp/go-build",
+		"-e", "GOPATH=/tmp/go",
+	}
+	// Keep the artifacts owned by the caller.
+	if uid, gid := os.Getuid(), os.Getgid(); uid >= 0 && gid >= 0 {
+		run = append(run, "-u", fmt.Sprintf("%d:%d", uid, gid))
+	}
+	for _, kv := range b.env(p) {
+		if k, v, _ := strings.Cut(kv, "="); hostPathEnv[k] && tc.Env[k] != v {
+			continue
+		}
+		run = append(run, "-e", kv)
+	}
+	run = append(run, tc.RunArgs...)
+	run = append(run, tc.Image, "go")
+
+	// Point -o at the mounted output directory.
+	args = append([]string(nil), args...)
+	for i := range args {
+		if args[i] == "-o" && i+1 < len(args) {
+			args[i+1] = "/out/" + filepath.ToSlash(outputName)
+			break
+		}
+	}
+	cmd := exec.CommandContext(ctx, engine, append(run, args...)...)
+	cmd.Cancel = func() error {
+		exec.Command(engine, "kill", name).Run()
+		return cmd.Process.Kill()
+	}
+	return cmd, nil
+}
+
+// stripContainerName removes the per-run container name from args.
+func stripContainerName(args []string) []string {
+	i := slices.Index(args, "--name")
+	if i < 0 || i+1 >= len(args) {
+		return args
+	}
+	return slices.Delete(slices.Clone(args), i, i+2)
+}
@@ -0,0 +1,138 @@
+package build
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestToolchainCGO(t *testing.T) {
+	cc, err := exec.LookPath("gcc")
+	if err != nil {
+		t.Skip("gcc not available")
+	}
+	src := t.TempDir()
+	writeMainModule(t, src, "package main\n\n// int answer(void) { return 42; }\nimport \"C\"\nimport \"fmt\"\n\nfunc main() { fmt.Print(C.answer()) }\n")
+	out := t.TempDir()
+	host := Platform{OS: runtime.GOOS, Arch: runtime.GOARCH}
+
+	err = Builder(
+		WithPath(out),
+		WithDir(src),
+		WithPackage("./cmd/hello"),
+		WithPlatforms(host),
+		WithEnv("CGO_ENABLED", "0"), // The toolchain must win over Option.Env.
+		WithToolchain(host.String(), Toolchain{CC: cc, Env: map[string]string{"CGO_ENABLED": "1"}}),
+	)
+	if err != nil {
+		t.Fatalf("Builder failed: %v", err)
+	}
+	got, err := exec.Command(filepath.Join(out, "app_"+host.OS+"_"+host.Arch)).Output()
+	if err != nil || string(got) != "42" {
+		t.Fatalf("output = %q, %v", got, err)
+	}
+}
+
+func TestToolchainContainerCommand(t *testing.T) {
+	arm64 := Platform{OS: "linux", Arch: "arm64"}
+	option := newOption(
+		WithPath(t.TempDir()),
+		WithDir(t.TempDir()),
+		WithPackage("./cmd/hello"),
+		WithTags("sqlite"),
+		WithEnv("GOCACHE", "/host/cache"),
+		WithEnv("GOFLAGS", "-mod=mod"),
+		WithToolchain(arm64.String(), Toolchain{
+			Image:   "cross:latest",
+			Runtime: "podman",
+			CC:      "aarch64-linux-gnu-gcc",
+			Sysroot: "/usr/aarch64-linux-gnu",
+		}),
+	)
+	b, err := newBuilder(option)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd, path, err := b.command(context.Background(), arm64, b.data(arm64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(cmd.Path) != "podman" || path != filepath.Join(b.outDir, "app_linux_arm64") {
+		t.Fatalf("cmd = %v, path = %s", cmd.Args, path)
+	}
+	if cmd.Cancel == nil {
+		t.Fatal("container command cannot be cancelled")
+	}
+	args := strings.Join(cmd.Args, " ")
+	for _, want := range []string{
+		"run --rm --name go-build-linux-arm64-",
+		"-v " + option.Dir + ":/src",
+		"-e GOARCH=arm64",
+		"-e CGO_ENABLED=1",
+		"-e CC=aarch64-linux-gnu-gcc",
+		"-e CGO_LDFLAGS=--sysroot=/usr/aarch64-linux-gnu",
+		"-e GOFLAGS=-mod=mod",
+		"cross:latest go build -o /out/app_linux_arm64 -tags sqlite ./cmd/hello",
+	} {
+		if !strings.Contains(args, want) {
+			t.Errorf("args lack %q:\n%s", want, args)
+		}
+	}
+
+	// Host paths of Option.Env are not mounted in the container.
+	if strings.Contains(args, "/host/cache") {
+		t.Errorf("args pass the host GOCACHE:\n%s", args)
+	}
+
+	// Container names are unique per run but do not change the cache key.
+	again, _, err := b.command(context.Background(), arm64, b.data(arm64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slices.Equal(cmd.Args, again.Args) || !slices.Equal(stripContainerName(cmd.Args), stripContainerName(again.Args)) {
+		t.Fatalf("container names: %v, %v", cmd.Args, again.Args)
+	}
+
+	// Platforms without a toolchain still build on the host.
+	amd64 := Platform{OS: "linux", Arch: "amd64"}
+	cmd, _, err = b.command(context.Background(), amd64, b.data(amd64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmd.Args[0] != "go" || slices.Contains(cmd.Env, "CC=aarch64-linux-gnu-gcc") {
+		t.Fatalf("host command = %v", cmd.Args)
+	}
+}
+
+func TestToolchainContainerVerifyOption(t *testing.T) {
+	arm64 := Platform{OS: "linux", Arch: "arm64"}
+	root := t.TempDir()
+	option := verifyOption(newOption(
+		WithDir(t.TempDir()),
+		WithPlatforms(arm64),
+		WithToolchain(arm64.String(), Toolchain{Image: "cross:latest"}),
+	), root, "1970-01-01T00:00:00Z", "/host/mod")
+	b, err := newBuilder(option)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd, _, err := b.command(context.Background(), arm64, b.data(arm64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := strings.Join(cmd.Args, " ")
+	if strings.Contains(args, "/host/mod") || strings.Contains(args, "-e GOPATH="+root) || strings.Contains(args, "-e GOCACHE="+root) {
+		t.Fatalf("args pass host cache paths:\n%s", args)
+	}
+	for _, want := range []string{"-e GOCACHE=/tmp/go-build", "-e GOPATH=/tmp/go", "-trimpath"} {
+		if !strings.Contains(args, want) {
+			t.Errorf("args lack %q:\n%s", want, args)
+		}
+	}
+}
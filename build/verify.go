@@ -0,0 +1,143 @@
+package build
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotReproducible is returned by Verify when two builds of a platform differ.
+var ErrNotReproducible = errors.New("build: not reproducible")
+
+// VerifyResult reports whether a platform builds reproducibly.
+type VerifyResult struct {
+	Platform     Platform
+	Hashes       [2]string // SHA-256 of the binary of each build.
+	Reproducible bool
+	Err          error
+}
+
+// Verify builds every platform of option twice, each time in a fresh temporary
+// GOPATH, GOCACHE and output directory with -trimpath, and compares the
+// binaries. The build date is fixed to SOURCE_DATE_EPOCH, or the Unix epoch
+// when unset, unless option.Date is set. The module cache is shared, since its
+// content is verified against go.sum. Platforms built in a toolchain container
+// get these fresh directories from the container itself. Packaging, image,
+// size and event options are ignored.
+//
+// The error wraps ErrNotReproducible and names the platforms whose binaries
+// differ, or joins the build failures.
+func Verify(ctx context.Context, option *Option) ([]VerifyResult, error) {
+	var (
+		modCache []byte
+		err      error
+	)
+	if hostBuilds(option) {
+		if modCache, err = exec.CommandContext(ctx, "go", "env", "GOMODCACHE").Output(); err != nil {
+			return nil, fmt.Errorf("failed to get module cache: %w", err)
+		}
+	}
+	date := option.Date
+	if date == "" {
+		if date, err = sourceDateEpoch(); err != nil {
+			return nil, err
+		}
+	}
+
+	var runs [2][]Result
+	for i := range runs {
+		root, err := os.MkdirTemp("", "build-verify-")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(root)
+
+		opt := verifyOption(option, root, date, strings.TrimSpace(string(modCache)))
+		runs[i], err = BuildResultsContext(ctx, opt)
+		if err != nil {
+			return nil, fmt.Errorf("build %d: %w", i+1, err)
+		}
+	}
+
+	results := make([]VerifyResult, len(runs[0]))
+	var differ []string
+	for i := range results {
+		r := &results[i]
+		r.Platform = runs[0][i].Platform
+		for j := range runs {
+			if r.Hashes[j], _, r.Err = hashFile(runs[j][i].Path); r.Err != nil {
+				break
+			}
+		}
+		if r.Err != nil {
+			continue
+		}
+		r.Reproducible = r.Hashes[0] == r.Hashes[1]
+		if !r.Reproducible {
+			differ = append(differ, r.Platform.String())
+		}
+	}
+	if len(differ) > 0 {
+		return results, fmt.Errorf("%w: %s", ErrNotReproducible, strings.Join(differ, ", "))
+	}
+	return results, nil
+}
+
+// verifyOption returns a copy of option that builds into root with a fresh
+// GOPATH and GOCACHE and only produces the binaries. The host paths are only
+// set when a platform builds on the host.
+func verifyOption(option *Option, root, date, modCache string) *Option {
+	opt := *option
+	opt.Path = filepath.Join(root, "out")
+	opt.TrimPath = true
+	opt.Date = date
+	opt.Archive, opt.Checksums, opt.Manifest, opt.ZipMode, opt.Incremental = false, false, false, false, false
+	opt.SizeAnalysis, opt.SizeBaseline, opt.SizeBudgets = false, "", nil
+	opt.Image, opt.OnEvent = nil, nil
+	if !hostBuilds(option) {
+		return &opt
+	}
+	opt.Env = maps.Clone(option.Env)
+	if opt.Env == nil {
+		opt.Env = make(map[string]string)
+	}
+	opt.Env["GOPATH"] = filepath.Join(root, "gopath")
+	opt.Env["GOCACHE"] = filepath.Join(root, "gocache")
+	opt.Env["GOMODCACHE"] = modCache
+	return &opt
+}
+
+// hostBuilds reports whether any platform of option builds on the host rather
+// than in a toolchain container.
+func hostBuilds(option *Option) bool {
+	platforms := option.Platforms
+	if len(platforms) == 0 {
+		platforms = Platforms
+	}
+	for _, p := range platforms {
+		if option.Toolchains[p.String()].Image == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceDateEpoch returns SOURCE_DATE_EPOCH as an RFC 3339 date, or the Unix
+// epoch when unset.
+func sourceDateEpoch() (string, error) {
+	var sec int64
+	if v := os.Getenv("SOURCE_DATE_EPOCH"); v != "" {
+		var err error
+		if sec, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return "", fmt.Errorf("invalid SOURCE_DATE_EPOCH %q: %w", v, err)
+		}
+	}
+	return time.Unix(sec, 0).UTC().Format(time.RFC3339), nil
+}
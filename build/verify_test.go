@@ -0,0 +1,93 @@
+package build
+
+import (
+	"context"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the standard library twice from an empty cache")
+	}
+	src := t.TempDir()
+	writeMainModule(t, src, helloSource)
+
+	option := newOption(
+		WithDir(src),
+		WithPackage("./cmd/hello"),
+		WithPlatforms(Platform{OS: runtime.GOOS, Arch: runtime.GOARCH}),
+		WithLDFlags("-X main.version={{.Date}}"),
+	)
+
+	results, err := Verify(context.Background(), option)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if len(results) != 1 || !results[0].Reproducible || results[0].Hashes[0] == "" {
+		t.Fatalf("results = %+v", results)
+	}
+}
+
+func TestVerifyOption(t *testing.T) {
+	option := newOption(
+		WithEnv("CGO_ENABLED", "0"),
+		WithArchive(true),
+		WithSizeAnalysis(true),
+		WithSizeBaseline("old/manifest.json"),
+		WithSizeBudget("*", 1),
+		WithImage(ImageOptions{}),
+		WithOnEvent(func(Event) { t.Error("unexpected event") }),
+	)
+	opt := verifyOption(option, "/tmp/verify", "1970-01-01T00:00:00Z", "/mod")
+	if opt.Archive || opt.SizeAnalysis || opt.SizeBaseline != "" || opt.SizeBudgets != nil || opt.Image != nil || opt.OnEvent != nil {
+		t.Fatalf("packaging, size or event options kept: %+v", opt)
+	}
+	if !opt.TrimPath || opt.Env["CGO_ENABLED"] != "0" || opt.Env["GOMODCACHE"] != "/mod" {
+		t.Fatalf("opt = %+v", opt)
+	}
+	if _, ok := option.Env["GOCACHE"]; ok || !option.SizeAnalysis {
+		t.Fatal("option was modified")
+	}
+}
+
+func TestVerifyOptionContainer(t *testing.T) {
+	arm64 := Platform{OS: "linux", Arch: "arm64"}
+	amd64 := Platform{OS: "linux", Arch: "amd64"}
+	tc := WithToolchain(arm64.String(), Toolchain{Image: "cross:latest"})
+
+	// Only container builds: no host paths at all.
+	opt := verifyOption(newOption(WithPlatforms(arm64), tc), "/tmp/verify", "", "/mod")
+	if len(opt.Env) != 0 {
+		t.Fatalf("container-only verify sets host paths: %v", opt.Env)
+	}
+
+	// Mixed builds: the host build gets the fresh directories, the container does not.
+	opt = verifyOption(newOption(WithDir(t.TempDir()), WithPlatforms(arm64, amd64), tc), t.TempDir(), "", "/mod")
+	b, err := newBuilder(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, _, err := b.command(context.Background(), amd64, b.data(amd64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(host.Env, "GOMODCACHE=/mod") {
+		t.Fatalf("host env lacks GOMODCACHE: %v", host.Env)
+	}
+	container, _, err := b.command(context.Background(), arm64, b.data(arm64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slices.Contains(container.Args, "GOMODCACHE=/mod") || slices.Contains(container.Args, "GOCACHE="+opt.Env["GOCACHE"]) {
+		t.Fatalf("container args pass host paths: %v", container.Args)
+	}
+}
+
+func TestSourceDateEpoch(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	if got, err := sourceDateEpoch(); err != nil || got != "2023-11-14T22:13:20Z" {
+		t.Fatalf("sourceDateEpoch() = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,127 @@
+package build
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultVersionFile is the version file read by ResolveVersion, relative to the build directory.
+const DefaultVersionFile = "VERSION"
+
+// VersionedOutput is the output name template used when versioning is enabled,
+// e.g. "app-v1.2.3-linux-amd64".
+const VersionedOutput = "{{.Name}}-{{.Version}}-{{.OS}}-{{.Arch}}{{.Variant}}{{.Ext}}"
+
+// ErrNoVersion is returned by ResolveVersion when neither the version file nor
+// git provides a version.
+var ErrNoVersion = errors.New("build: no version found")
+
+// WithVersion sets an explicit version, overriding the version file and git.
+func WithVersion(version string) OptionFunc {
+	return func(o *Option) {
+		o.Version = version
+	}
+}
+
+// WithVersioning enables version resolution, -X injection of version, commit and
+// date into VersionPackage, and VersionedOutput artifact names.
+func WithVersioning(enabled bool) OptionFunc {
+	return func(o *Option) {
+		o.Versioned = enabled
+	}
+}
+
+// WithVersionFile sets the version file; relative paths are resolved against Dir.
+func WithVersionFile(path string) OptionFunc {
+	return func(o *Option) {
+		o.VersionFile = path
+	}
+}
+
+// WithVersionPackage sets the package whose version, commit and date variables
+// are set via -X; defaults to "main".
+func WithVersionPackage(pkg string) OptionFunc {
+	return func(o *Option) {
+		o.VersionPackage = pkg
+	}
+}
+
+// ResolveVersion returns the version for the module in dir: the content of
+// versionFile (DefaultVersionFile when empty) if it exists, otherwise the output
+// of "git describe --tags --always --dirty".
+func ResolveVersion(dir, versionFile string) (string, error) {
+	if versionFile == "" {
+		versionFile = DefaultVersionFile
+	}
+	if !filepath.IsAbs(versionFile) {
+		versionFile = filepath.Join(dir, versionFile)
+	}
+	data, err := os.ReadFile(versionFile)
+	switch {
+	case err == nil:
+		if v := strings.TrimSpace(string(data)); v != "" {
+			return v, nil
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return "", fmt.Errorf("read %s: %w", versionFile, err)
+	}
+
+	if v, err := git(dir, "describe", "--tags", "--always", "--dirty"); err == nil && v != "" {
+		return v, nil
+	}
+	return "", ErrNoVersion
+}
+
+// ResolveCommit returns the short commit hash of HEAD in dir.
+func ResolveCommit(dir string) (string, error) {
+	return git(dir, "rev-parse", "--short", "HEAD")
+}
+
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// versionLDFlags returns the -X flags injecting version, commit and date,
+// quoting values with spaces as go build splits -ldflags on them.
+func versionLDFlags(pkg string, data TemplateData) (string, error) {
+	if pkg == "" {
+		pkg = "main"
+	}
+	var flags []string
+	for _, kv := range [][2]string{{"version", data.Version}, {"commit", data.Commit}, {"date", data.Date}} {
+		if kv[1] == "" {
+			continue
+		}
+		field, err := quoteFlag(fmt.Sprintf("%s.%s=%s", pkg, kv[0], kv[1]))
+		if err != nil {
+			return "", err
+		}
+		flags = append(flags, "-X "+field)
+	}
+	return strings.Join(flags, " "), nil
+}
+
+// quoteFlag quotes s as one field of a go build flag list, which splits on
+// spaces and honours single and double quotes without escapes.
+func quoteFlag(s string) (string, error) {
+	if !strings.ContainsAny(s, " \t\n\r'\"") {
+		return s, nil
+	}
+	if !strings.Contains(s, "'") {
+		return "'" + s + "'", nil
+	}
+	if !strings.Contains(s, `"`) {
+		return `"` + s + `"`, nil
+	}
+	return "", fmt.Errorf("cannot quote %q for -ldflags: it contains both quote characters", s)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	translator "github.com/package-register/go-toolkit/trans"
+)
+
+// config 命令行配置，可由配置文件提供，环境变量优先
+type config struct {
+	Provider string `json:"provider"`
+	Xfyun    struct {
+		AppID   string `json:"appid"`
+		APIKey  string `json:"apiKey"`
+		Secret  string `json:"secret"`
+		BaseURL string `json:"baseUrl"`
+	} `json:"xfyun"`
+	Baidu struct {
+		AppID  string `json:"appid"`
+		Secret string `json:"secret"`
+	} `json:"baidu"`
+	DeepL struct {
+		AuthKey string `json:"authKey"`
+		BaseURL string `json:"baseUrl"`
+	} `json:"deepl"`
+	Libre struct {
+		BaseURL string `json:"baseUrl"`
+		APIKey  string `json:"apiKey"`
+	} `json:"libretranslate"`
+}
+
+// defaultConfigPath 返回默认配置文件路径 $XDG_CONFIG_HOME/go-toolkit/trans.json
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "go-toolkit", "trans.json")
+}
+
+// loadConfig 读取配置文件（不存在时忽略）并以环境变量覆盖
+func loadConfig(path string, explicit bool) (*config, error) {
+	cfg := &config{Provider: "xfyun"}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			if err := json.Unmarshal(data, cfg); err != nil {
+				return nil, fmt.Errorf("parse config %s failed: %w", path, err)
+			}
+		case !os.IsNotExist(err) || explicit:
+			return nil, fmt.Errorf("read config failed: %w", err)
+		}
+	}
+
+	env := func(dst *string, key string) {
+		if v := os.Getenv(key); v != "" {
+			*dst = v
+		}
+	}
+	env(&cfg.Provider, "TRANS_PROVIDER")
+	env(&cfg.Xfyun.AppID, "XFYUN_APPID")
+	env(&cfg.Xfyun.APIKey, "XFYUN_API_KEY")
+	env(&cfg.Xfyun.Secret, "XFYUN_API_SECRET")
+	env(&cfg.Xfyun.BaseURL, "XFYUN_BASE_URL")
+	env(&cfg.Baidu.AppID, "BAIDU_APPID")
+	env(&cfg.Baidu.Secret, "BAIDU_SECRET")
+	env(&cfg.DeepL.AuthKey, "DEEPL_AUTH_KEY")
+	env(&cfg.DeepL.BaseURL, "DEEPL_BASE_URL")
+	env(&cfg.Libre.BaseURL, "LIBRETRANSLATE_URL")
+	env(&cfg.Libre.APIKey, "LIBRETRANSLATE_API_KEY")
+	return cfg, nil
+}
+
+// newProvider 按配置创建翻译服务，统一使用 BCP-47 语言标签
+func newProvider(cfg *config) (translator.Provider, error) {
+	policy := translator.DefaultPolicy()
+
+	var p translator.Provider
+	switch cfg.Provider {
+	case "xfyun":
+		if cfg.Xfyun.AppID == "" || cfg.Xfyun.APIKey == "" || cfg.Xfyun.Secret == "" {
+			return nil, fmt.Errorf("xfyun requires XFYUN_APPID, XFYUN_API_KEY and XFYUN_API_SECRET")
+		}
+		opts := []translator.Option{
+			translator.WithAppID(cfg.Xfyun.AppID),
+			translator.WithAPIKey(cfg.Xfyun.APIKey),
+			translator.WithSecret(cfg.Xfyun.Secret),
+			translator.WithPolicy(policy),
+		}
+		if cfg.Xfyun.BaseURL != "" {
+			u, err := url.Parse(cfg.Xfyun.BaseURL)
+			if err != nil || u.Host == "" {
+				return nil, fmt.Errorf("invalid xfyun base url %q", cfg.Xfyun.BaseURL)
+			}
+			opts = append(opts, translator.WithScheme(u.Scheme), translator.WithHost(u.Host))
+		}
+		p = translator.New(opts...)
+	case "baidu":
+		if cfg.Baidu.AppID == "" || cfg.Baidu.Secret == "" {
+			return nil, fmt.Errorf("baidu requires BAIDU_APPID and BAIDU_SECRET")
+		}
+		p = translator.NewBaidu(translator.BaiduConfig{AppID: cfg.Baidu.AppID, Secret: cfg.Baidu.Secret, Policy: &policy})
+	case "deepl":
+		if cfg.DeepL.AuthKey == "" {
+			return nil, fmt.Errorf("deepl requires DEEPL_AUTH_KEY")
+		}
+		p = translator.NewDeepL(translator.DeepLConfig{AuthKey: cfg.DeepL.AuthKey, BaseURL: cfg.DeepL.BaseURL, Policy: &policy})
+	case "libretranslate":
+		p = translator.NewLibreTranslate(translator.LibreConfig{BaseURL: cfg.Libre.BaseURL, APIKey: cfg.Libre.APIKey, Policy: &policy})
+	default:
+		return nil, fmt.Errorf("unknown provider %q", cfg.Provider)
+	}
+	return translator.WithLanguages(p, nil), nil
+}
@@ -0,0 +1,222 @@
+// Command trans 基于 trans 包的命令行翻译工具。
+//
+// 用法:
+//
+//	trans [flags] [text ...]
+//
+// 待翻译文本依次取自命令行参数、-f 指定的文件或标准输入。
+// 凭据从环境变量（XFYUN_APPID、XFYUN_API_KEY、XFYUN_API_SECRET、BAIDU_APPID、
+// BAIDU_SECRET、DEEPL_AUTH_KEY、LIBRETRANSLATE_URL 等）或 -config 配置文件读取。
+//
+// 示例:
+//
+//	trans -to en 你好世界
+//	echo "Bonjour" | trans -provider deepl -to zh
+//	trans -batch lines.txt -json > out.json
+//	trans -resource locales/zh.json -o locales/en.json -to en
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	translator "github.com/package-register/go-toolkit/trans"
+)
+
+// stringList 可重复的字符串参数
+type stringList []string
+
+func (s *stringList) String() string     { return strings.Join(*s, ",") }
+func (s *stringList) Set(v string) error { *s = append(*s, v); return nil }
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "trans:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("trans", flag.ContinueOnError)
+	var (
+		files      stringList
+		configPath = fs.String("config", "", "配置文件路径（默认 "+defaultConfigPath()+"）")
+		provider   = fs.String("provider", "", "翻译服务: xfyun、baidu、deepl、libretranslate")
+		from       = fs.String("from", translator.AutoLanguage, "源语言（BCP-47），auto 表示自动检测")
+		to         = fs.String("to", "en", "目标语言（BCP-47）")
+		batch      = fs.String("batch", "", "批量翻译文件，每行一条文本")
+		asJSON     = fs.Bool("json", false, "以 JSON 输出翻译结果")
+		resource   = fs.String("resource", "", "翻译 i18n 资源文件（.json/.yaml/.po）")
+		output     = fs.String("o", "", "资源文件输出路径（默认 <name>.<to>.<ext>）")
+	)
+	fs.Var(&files, "f", "从文件读取待翻译文本，可重复")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	path, explicit := *configPath, *configPath != ""
+	if !explicit {
+		path = defaultConfigPath()
+	}
+	cfg, err := loadConfig(path, explicit)
+	if err != nil {
+		return err
+	}
+	if *provider != "" {
+		cfg.Provider = *provider
+	}
+	p, err := newProvider(cfg)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if *resource != "" {
+		dst := *output
+		if dst == "" {
+			dst = resourceOutput(*resource, *to)
+		}
+		opts := translator.ResourceOptions{From: *from, To: *to}
+		if err := translator.TranslateResourceFile(ctx, p, *resource, dst, opts); err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, dst)
+		return nil
+	}
+
+	if *batch != "" {
+		lines, err := readLines(*batch)
+		if err != nil {
+			return err
+		}
+		results, err := translateLines(ctx, p, lines, *from, *to)
+		if err != nil {
+			return err
+		}
+		return writeResults(stdout, results, *asJSON, true)
+	}
+
+	var texts []string
+	switch {
+	case len(files) > 0:
+		texts, err = readFiles(files)
+	case fs.NArg() > 0 && fs.Arg(0) != "-":
+		texts = []string{strings.Join(fs.Args(), " ")}
+	default:
+		var data []byte
+		data, err = io.ReadAll(stdin)
+		texts = []string{string(data)}
+	}
+	if err != nil {
+		return err
+	}
+
+	results := make([]*translator.TranslationResult, len(texts))
+	for i, text := range texts {
+		// 文档模式自动切分长文本，短文本只发送一次请求
+		res, err := translator.TranslateDocument(ctx, p, translator.Request{Text: text, From: *from, To: *to}, translator.DocumentOptions{})
+		if err != nil {
+			return fmt.Errorf("translate text %d: %w", i+1, err)
+		}
+		results[i] = res
+	}
+
+	return writeResults(stdout, results, *asJSON, false)
+}
+
+// translateLines 以一次 TranslateBatch 翻译各行，空行原样保留以保持行对应
+func translateLines(ctx context.Context, p translator.Provider, lines []string, from, to string) ([]*translator.TranslationResult, error) {
+	results := make([]*translator.TranslationResult, len(lines))
+	var (
+		reqs  []translator.Request
+		index []int
+	)
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			results[i] = &translator.TranslationResult{Source: line, Target: line, From: from, To: to}
+			continue
+		}
+		reqs = append(reqs, translator.Request{Text: line, From: from, To: to})
+		index = append(index, i)
+	}
+	if len(reqs) == 0 {
+		return results, nil
+	}
+
+	batch, err := p.TranslateBatch(ctx, reqs)
+	if err != nil {
+		return nil, fmt.Errorf("translate batch: %w", err)
+	}
+	for i, res := range batch {
+		results[index[i]] = res
+	}
+	return results, nil
+}
+
+// writeResults 输出翻译结果，list 为 true 时 JSON 始终输出数组
+func writeResults(w io.Writer, results []*translator.TranslationResult, asJSON, list bool) error {
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if !list && len(results) == 1 {
+			return enc.Encode(results[0])
+		}
+		return enc.Encode(results)
+	}
+	for _, res := range results {
+		fmt.Fprintln(w, strings.TrimRight(res.Target, "\n"))
+	}
+	return nil
+}
+
+// resourceOutput 由源文件名生成默认输出路径，如 zh.json → zh.en.json
+func resourceOutput(src, lang string) string {
+	dot := strings.LastIndex(src, ".")
+	if dot < 0 {
+		return src + "." + lang
+	}
+	return src[:dot] + "." + lang + src[dot:]
+}
+
+// readLines 按行读取文件，行内容原样保留
+func readLines(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	return lines, sc.Err()
+}
+
+func readFiles(paths []string) ([]string, error) {
+	texts := make([]string, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		texts = append(texts, string(data))
+	}
+	return texts, nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	translator "github.com/package-register/go-toolkit/trans"
+	"github.com/package-register/go-toolkit/trans/transtest"
+)
+
+// newServer 启动模拟服务并通过环境变量将命令行指向它
+func newServer(t *testing.T) *transtest.Server {
+	t.Helper()
+	srv := transtest.NewServer()
+	t.Cleanup(srv.Close)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("TRANS_PROVIDER", "xfyun")
+	t.Setenv("XFYUN_APPID", transtest.AppID)
+	t.Setenv("XFYUN_API_KEY", transtest.APIKey)
+	t.Setenv("XFYUN_API_SECRET", transtest.Secret)
+	t.Setenv("XFYUN_BASE_URL", srv.URL)
+	return srv
+}
+
+func TestRunArgs(t *testing.T) {
+	srv := newServer(t)
+
+	var out bytes.Buffer
+	if err := run([]string{"-to", "en", "你好", "世界"}, strings.NewReader(""), &out); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != "en:你好 世界\n" {
+		t.Fatalf("output = %q", got)
+	}
+	if reqs := srv.Requests(); len(reqs) != 1 || reqs[0].Text != "你好 世界" {
+		t.Fatalf("unexpected requests: %+v", reqs)
+	}
+}
+
+func TestRunStdin(t *testing.T) {
+	newServer(t)
+
+	var out bytes.Buffer
+	if err := run([]string{"-to", "en", "-json"}, strings.NewReader("你好"), &out); err != nil {
+		t.Fatal(err)
+	}
+	var res translator.TranslationResult
+	if err := json.Unmarshal(out.Bytes(), &res); err != nil {
+		t.Fatalf("output %q: %v", out.String(), err)
+	}
+	if res.Source != "你好" || res.Target != "en:你好" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+}
+
+func TestRunBatchJSON(t *testing.T) {
+	srv := newServer(t)
+
+	path := filepath.Join(t.TempDir(), "lines.txt")
+	if err := os.WriteFile(path, []byte("  缩进\n\n末行 \n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := run([]string{"-to", "en", "-batch", path, "-json"}, strings.NewReader(""), &out); err != nil {
+		t.Fatal(err)
+	}
+	var results []translator.TranslationResult
+	if err := json.Unmarshal(out.Bytes(), &results); err != nil {
+		t.Fatalf("output %q: %v", out.String(), err)
+	}
+	want := []string{"en:  缩进", "", "en:末行 "}
+	if len(results) != len(want) {
+		t.Fatalf("expected %d results, got %+v", len(want), results)
+	}
+	for i, res := range results {
+		if res.Target != want[i] {
+			t.Errorf("results[%d].Target = %q, want %q", i, res.Target, want[i])
+		}
+	}
+	// 空行不发送请求，其余行保留首尾空白
+	if reqs := srv.Requests(); len(reqs) != 2 || reqs[0].Text != "  缩进" || reqs[1].Text != "末行 " {
+		t.Fatalf("unexpected requests: %+v", reqs)
+	}
+}
+
+func TestRunResource(t *testing.T) {
+	newServer(t)
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "zh.json")
+	if err := os.WriteFile(src, []byte(`{"greeting": "你好", "menu": {"open": "打开"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := run([]string{"-to", "en", "-resource", src}, strings.NewReader(""), &out); err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(dir, "zh.en.json")
+	if got := strings.TrimSpace(out.String()); got != dst {
+		t.Fatalf("output = %q, want %q", got, dst)
+	}
+
+	data, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res struct {
+		Greeting string `json:"greeting"`
+		Menu     struct {
+			Open string `json:"open"`
+		} `json:"menu"`
+	}
+	if err := json.Unmarshal(data, &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Greeting != "en:你好" || res.Menu.Open != "en:打开" {
+		t.Fatalf("unexpected resource: %s", data)
+	}
+}
@@ -4,8 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net"
+	"slices"
 	"sync"
 	"time"
 
@@ -18,23 +18,6 @@ const (
 	timeout       = 25 * time.Second // Increased timeout to be greater than announceIntv
 )
 
-// Logger 接口定义了日志方法
-type Logger interface {
-	Info(msg string, args ...any)
-	Error(msg string, args ...any)
-}
-
-// StdLogger 是 Logger 接口的标准实现
-type StdLogger struct{}
-
-func (l *StdLogger) Info(msg string, args ...any) {
-	log.Printf("[INFO] "+msg, args...)
-}
-
-func (l *StdLogger) Error(msg string, args ...any) {
-	log.Printf("[ERROR] "+msg, args...)
-}
-
 // MessageEnvelope 定义了在网络中传输的消息结构
 type MessageEnvelope struct {
 	FromUUID string          `json:"fromUuid"`
@@ -50,34 +33,61 @@ type CommandHandler func(from net.Addr, env MessageEnvelope)
 
 // Device 结构体表示发现到的设备信息
 type Device struct {
-	UUID     string
-	Name     string
-	IP       string
-	Port     int
-	Version  string
-	LastSeen time.Time
+	UUID     string    `json:"uuid"`
+	Name     string    `json:"name"`
+	IP       string    `json:"ip"`
+	Port     int       `json:"port"`
+	Version  string    `json:"version"`
+	Groups   []string  `json:"groups,omitempty"` // 参与的选举组
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// DeviceEventType 设备事件类型
+type DeviceEventType string
+
+const (
+	DeviceJoined DeviceEventType = "joined" // 发现新设备
+	DeviceLeft   DeviceEventType = "left"   // 设备过期移除
+)
+
+// DeviceEvent 描述设备表的一次变更
+type DeviceEvent struct {
+	Type   DeviceEventType `json:"type"`
+	Device Device          `json:"device"`
+	Time   time.Time       `json:"time"`
 }
 
+// DeviceListener 设备事件监听函数，在内部 goroutine 中同步调用，不应阻塞
+type DeviceListener func(evt DeviceEvent)
+
 // Discovery 结构体封装了设备发现和通信的逻辑
 type Discovery struct {
-	uuid          string
-	name          string
-	ip            string
-	port          int
-	version       string
-	logger        Logger
-	ctx           context.Context
-	cancel        context.CancelFunc
-	mu            sync.RWMutex
-	handlers      map[string]CommandHandler
-	devices       map[string]*Device
-	pending       map[string]chan MessageEnvelope
+	uuid           string
+	name           string
+	ip             string
+	port           int
+	version        string
+	logger         Logger
+	ctx            context.Context
+	cancel         context.CancelFunc
+	mu             sync.RWMutex
+	handlers       map[string]CommandHandler
+	devices        map[string]*Device
+	pending        map[string]chan MessageEnvelope
+	listeners      []DeviceListener
+	groups         []string // 本节点参与的选举组，随 announce 广播
+	stats          map[string]*PeerStats
+	hubOnce        sync.Once
+	hub            *eventHub
 	multicastConns []*net.UDPConn // Changed to slice for multiple connections
-	unicastConn   *net.UDPConn
+	unicastConn    *net.UDPConn
 }
 
-// NewDiscovery 创建一个新的 Discovery 实例
+// NewDiscovery 创建一个新的 Discovery 实例，logger 为 nil 时不输出日志
 func NewDiscovery(name, ver string, logger Logger) *Discovery {
+	if logger == nil {
+		logger = NopLogger{}
+	}
 	ip, _ := getLocalIP() // This will need to be revisited for multi-NIC
 	port, _ := getAvailablePort()
 	ctx, cancel := context.WithCancel(context.Background())
@@ -93,6 +103,7 @@ func NewDiscovery(name, ver string, logger Logger) *Discovery {
 		handlers: make(map[string]CommandHandler),
 		devices:  make(map[string]*Device),
 		pending:  make(map[string]chan MessageEnvelope),
+		stats:    make(map[string]*PeerStats),
 	}
 }
 
@@ -103,6 +114,27 @@ func (d *Discovery) RegisterHandler(cmd string, handler CommandHandler) {
 	d.handlers[cmd] = handler
 }
 
+// OnDeviceEvent 注册设备事件监听器
+func (d *Discovery) OnDeviceEvent(l DeviceListener) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.listeners = append(d.listeners, l)
+}
+
+// joinGroup 将选举组加入 announce，使其它节点把本节点视为该组的候选者
+func (d *Discovery) joinGroup(group string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !slices.Contains(d.groups, group) {
+		d.groups = append(d.groups, group)
+	}
+}
+
+// UUID 返回本节点的 UUID
+func (d *Discovery) UUID() string {
+	return d.uuid
+}
+
 // Start 启动设备发现服务
 func (d *Discovery) Start() error {
 	maddr, err := net.ResolveUDPAddr("udp", multicastAddr)
@@ -120,7 +152,7 @@ func (d *Discovery) Start() error {
 	for _, iface := range interfaces {
 		mc, err := net.ListenMulticastUDP("udp", &iface, maddr)
 		if err != nil {
-			d.logger.Error("listen multicast UDP on interface %s failed: %v", iface.Name, err)
+			d.logger.Error("listen multicast UDP failed", "iface", iface.Name, "err", err)
 			continue // Try next interface
 		}
 		d.multicastConns = append(d.multicastConns, mc)
@@ -140,7 +172,7 @@ func (d *Discovery) Start() error {
 	go d.sendMulticastAnnounce()
 	go d.cleanupDevices()
 
-	d.logger.Info("Discovery 启动: %s (%s:%d)", d.name, d.ip, d.port)
+	d.logger.Info("Discovery 启动", "uuid", d.uuid, "name", d.name, "addr", fmt.Sprintf("%s:%d", d.ip, d.port))
 	return nil
 }
 
@@ -180,6 +212,7 @@ func (d *Discovery) RequestResponse(env MessageEnvelope, timeout time.Duration)
 		env.TaskID = uuid.New().String()
 	}
 	ch := make(chan MessageEnvelope, 1)
+	start := time.Now()
 
 	d.mu.Lock()
 	d.pending[env.TaskID] = ch
@@ -194,6 +227,7 @@ func (d *Discovery) RequestResponse(env MessageEnvelope, timeout time.Duration)
 
 	select {
 	case resp := <-ch:
+		d.recordRTT(resp.FromUUID, time.Since(start))
 		return resp, nil
 	case <-time.After(timeout):
 		d.mu.Lock()
@@ -229,12 +263,12 @@ func (d *Discovery) listenMulticast(conn *net.UDPConn) {
 				if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
 					continue
 				}
-				d.logger.Error("读取组播消息失败: %v", err)
+				d.logger.Error("读取组播消息失败", "err", err)
 				continue
 			}
 			var env MessageEnvelope
 			if err := json.Unmarshal(buf[:n], &env); err != nil {
-				d.logger.Error("解析组播消息失败: %v", err)
+				d.logger.Error("解析组播消息失败", "from", src.String(), "err", err)
 				continue
 			}
 			if env.FromUUID == d.uuid { // 忽略自己的消息
@@ -247,14 +281,15 @@ func (d *Discovery) listenMulticast(conn *net.UDPConn) {
 }
 
 func (d *Discovery) processReceivedMessage(from net.Addr, env MessageEnvelope) {
+	d.recordIn(env.FromUUID, from)
+
 	if env.Command == "announce" {
 		var info map[string]any
 		if err := json.Unmarshal(env.Payload, &info); err != nil {
-			d.logger.Error("解析 announce 消息 payload 失败: %v", err)
+			d.logger.Error("解析 announce 消息 payload 失败", "uuid", env.FromUUID, "err", err)
 			return
 		}
-		d.mu.Lock()
-		d.devices[env.FromUUID] = &Device{
+		dev := &Device{
 			UUID:     env.FromUUID,
 			Name:     fmt.Sprint(info["name"]),
 			IP:       fmt.Sprint(info["ip"]),
@@ -262,8 +297,21 @@ func (d *Discovery) processReceivedMessage(from net.Addr, env MessageEnvelope) {
 			Version:  fmt.Sprint(info["version"]),
 			LastSeen: time.Now(),
 		}
+		if groups, ok := info["groups"].([]any); ok {
+			for _, g := range groups {
+				dev.Groups = append(dev.Groups, fmt.Sprint(g))
+			}
+		}
+		d.mu.Lock()
+		_, known := d.devices[env.FromUUID]
+		d.devices[env.FromUUID] = dev
 		d.mu.Unlock()
-		d.logger.Info("发现新设备: %s (%s:%d)", info["name"], info["ip"], int(info["port"].(float64)))
+		if !known {
+			d.logger.Info("发现新设备", "uuid", dev.UUID, "name", dev.Name, "addr", fmt.Sprintf("%s:%d", dev.IP, dev.Port))
+			d.emit(DeviceJoined, dev)
+		} else {
+			d.logger.Debug("设备心跳", "uuid", dev.UUID)
+		}
 	}
 
 	d.mu.RLock()
@@ -283,7 +331,7 @@ func (d *Discovery) processReceivedMessage(from net.Addr, env MessageEnvelope) {
 	if ok {
 		go handler(from, env)
 	} else {
-		d.logger.Info("未注册命令处理器: %s", env.Command)
+		d.logger.Debug("未注册命令处理器", "command", env.Command, "uuid", env.FromUUID, "task_id", env.TaskID)
 	}
 }
 
@@ -295,14 +343,17 @@ func (d *Discovery) sendMulticastAnnounce() {
 		case <-d.ctx.Done():
 			return
 		case <-ticker.C:
+			d.mu.RLock()
+			groups := append([]string(nil), d.groups...)
+			d.mu.RUnlock()
 			env := MessageEnvelope{
 				SendType: "announce",
 				Command:  "announce",
 				TaskID:   uuid.New().String(),
-				Payload:  mustJSON(map[string]any{"name": d.name, "version": d.version, "ip": d.ip, "port": d.port, "uuid": d.uuid}),
+				Payload:  mustJSON(map[string]any{"name": d.name, "version": d.version, "ip": d.ip, "port": d.port, "uuid": d.uuid, "groups": groups}),
 			}
 			if err := d.Send(env); err != nil {
-				d.logger.Error("发送 announce 消息失败: %v", err)
+				d.logger.Error("发送 announce 消息失败", "err", err)
 			}
 		}
 	}
@@ -316,17 +367,38 @@ func (d *Discovery) cleanupDevices() {
 		case <-d.ctx.Done():
 			return
 		case <-ticker.C:
-			d.mu.Lock()
-			now := time.Now()
-			for k, dev := range d.devices {
-				if now.Sub(dev.LastSeen) > timeout {
-					delete(d.devices, k)
-					d.logger.Info("设备过期移除: %s", dev.UUID)
-				}
-			}
-			d.mu.Unlock()
+			d.expireDevices(time.Now())
+		}
+	}
+}
+
+// expireDevices 移除超时的设备及其通信统计
+func (d *Discovery) expireDevices(now time.Time) {
+	d.mu.Lock()
+	var expired []*Device
+	for k, dev := range d.devices {
+		if now.Sub(dev.LastSeen) > timeout {
+			delete(d.devices, k)
+			expired = append(expired, dev)
+			d.logger.Info("设备过期移除", "uuid", dev.UUID, "name", dev.Name)
 		}
 	}
+	d.pruneStatsLocked(now)
+	d.mu.Unlock()
+	for _, dev := range expired {
+		d.emit(DeviceLeft, dev)
+	}
+}
+
+// emit 通知所有设备事件监听器，调用方不能持有 d.mu
+func (d *Discovery) emit(typ DeviceEventType, dev *Device) {
+	d.mu.RLock()
+	listeners := append([]DeviceListener(nil), d.listeners...)
+	d.mu.RUnlock()
+	evt := DeviceEvent{Type: typ, Device: *dev, Time: time.Now()}
+	for _, l := range listeners {
+		l(evt)
+	}
 }
 
 func (d *Discovery) sendMulticast(data []byte) error {
@@ -343,7 +415,7 @@ func (d *Discovery) sendMulticast(data []byte) error {
 		_, err := conn.WriteToUDP(data, maddr)
 		if err != nil {
 			lastErr = fmt.Errorf("send multicast on %s failed: %w", conn.LocalAddr().String(), err)
-			d.logger.Error(lastErr.Error())
+			d.logger.Error("send multicast failed", "local", conn.LocalAddr().String(), "err", err)
 		}
 	}
 	return lastErr // Return the last error encountered, or nil if all succeeded
@@ -355,8 +427,11 @@ func (d *Discovery) sendUnicast(addr string, data []byte) error {
 		return fmt.Errorf("resolve unicast address failed: %w", err)
 	}
 	_ = d.unicastConn.SetWriteDeadline(time.Now().Add(2 * time.Second))
-	_, err = d.unicastConn.WriteToUDP(data, udpAddr)
-	return err
+	if _, err = d.unicastConn.WriteToUDP(data, udpAddr); err != nil {
+		return err
+	}
+	d.recordOut(addr)
+	return nil
 }
 
 // getActiveInterfaces returns a list of active network interfaces with at least one IPv4 address.
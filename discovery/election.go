@@ -0,0 +1,185 @@
+package discovery
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	defaultSettleTime    = announceIntv + 5*time.Second // 至少等待一轮 announce 后再参与选举
+	defaultCheckInterval = 5 * time.Second
+	defaultGroup         = "default"
+)
+
+// LeaderChange 描述一次领导者变更
+type LeaderChange struct {
+	Leader   string    // 当前领导者 UUID，为空表示尚未选出
+	IsLeader bool      // 本节点是否为领导者
+	Time     time.Time // 变更时间
+}
+
+// LeaderHandler 定义了领导者变更通知的函数签名，在选举 goroutine 中按顺序调用
+type LeaderHandler func(change LeaderChange)
+
+// ElectionOption 选举配置选项
+type ElectionOption func(*Election)
+
+// WithSettleTime 设置启动后参与选举前的等待时间，用于收集其它节点的 announce
+func WithSettleTime(d time.Duration) ElectionOption {
+	return func(e *Election) {
+		e.settle = d
+	}
+}
+
+// WithGroup 设置选举组，只有 announce 中声明参与同一组的节点才是候选者
+func WithGroup(group string) ElectionOption {
+	return func(e *Election) {
+		e.group = group
+	}
+}
+
+// WithCheckInterval 设置周期性重新选举的间隔
+func WithCheckInterval(d time.Duration) ElectionOption {
+	return func(e *Election) {
+		e.interval = d
+	}
+}
+
+// Election 基于 Discovery 设备表的 bully 式选举：
+// 参与同一选举组的存活节点（含本节点）中 UUID 最大者为领导者。
+// 所有节点看到的设备表最终一致，因此无需额外的选举消息即可收敛。
+type Election struct {
+	disc     *Discovery
+	group    string
+	settle   time.Duration
+	interval time.Duration
+	trigger  chan struct{} // 设备变更后唤醒选举 goroutine
+
+	notifyMu sync.Mutex // 保证变更通知按顺序送达
+	mu       sync.RWMutex
+	leader   string
+	started  time.Time
+	handlers []LeaderHandler
+}
+
+// NewElection 创建一个绑定到 Discovery 的选举实例
+func NewElection(d *Discovery, opts ...ElectionOption) *Election {
+	e := &Election{
+		disc:     d,
+		group:    defaultGroup,
+		settle:   defaultSettleTime,
+		interval: defaultCheckInterval,
+		trigger:  make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// OnChange 注册领导者变更通知
+func (e *Election) OnChange(h LeaderHandler) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.handlers = append(e.handlers, h)
+}
+
+// Start 开始参与选举，随 Discovery 停止而结束
+func (e *Election) Start() {
+	e.mu.Lock()
+	e.started = time.Now()
+	e.mu.Unlock()
+
+	e.disc.joinGroup(e.group)
+	// 设备事件在接收 goroutine 中同步触发，这里只做唤醒，
+	// 选举与变更通知都在 loop 中进行，避免处理函数阻塞消息接收
+	e.disc.OnDeviceEvent(func(DeviceEvent) {
+		select {
+		case e.trigger <- struct{}{}:
+		default:
+		}
+	})
+	go e.loop()
+}
+
+// IsLeader 返回本节点当前是否为领导者
+func (e *Election) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leader != "" && e.leader == e.disc.uuid
+}
+
+// Leader 返回当前领导者的 UUID，尚未选出时为空
+func (e *Election) Leader() string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leader
+}
+
+func (e *Election) loop() {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.disc.ctx.Done():
+			e.setLeader("")
+			return
+		case <-ticker.C:
+			e.evaluate()
+		case <-e.trigger:
+			e.evaluate()
+		}
+	}
+}
+
+// evaluate 根据当前设备表重新计算领导者
+func (e *Election) evaluate() {
+	e.mu.RLock()
+	settling := time.Since(e.started) < e.settle
+	e.mu.RUnlock()
+	if settling || e.disc.ctx.Err() != nil {
+		return
+	}
+
+	leader := e.disc.uuid
+	now := time.Now()
+	for _, dev := range e.disc.GetDevices() {
+		// 尚未被 cleanupDevices 移除但已超时的节点、未参与本组的节点不参与选举
+		if now.Sub(dev.LastSeen) > timeout || !slices.Contains(dev.Groups, e.group) {
+			continue
+		}
+		if dev.UUID > leader {
+			leader = dev.UUID
+		}
+	}
+	e.setLeader(leader)
+}
+
+func (e *Election) setLeader(leader string) {
+	e.notifyMu.Lock()
+	defer e.notifyMu.Unlock()
+
+	e.mu.Lock()
+	if e.leader == leader {
+		e.mu.Unlock()
+		return
+	}
+	e.leader = leader
+	handlers := append([]LeaderHandler(nil), e.handlers...)
+	e.mu.Unlock()
+
+	change := LeaderChange{
+		Leader:   leader,
+		IsLeader: leader != "" && leader == e.disc.uuid,
+		Time:     time.Now(),
+	}
+	if change.IsLeader {
+		e.disc.logger.Info("本节点成为领导者", "uuid", leader)
+	} else {
+		e.disc.logger.Info("领导者变更", "leader", leader)
+	}
+	for _, h := range handlers {
+		h(change)
+	}
+}
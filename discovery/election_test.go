@@ -0,0 +1,127 @@
+package discovery
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+func announce(d *Discovery, id string, groups ...string) {
+	d.processReceivedMessage(&net.UDPAddr{}, MessageEnvelope{
+		FromUUID: id,
+		SendType: "announce",
+		Command:  "announce",
+		Payload:  mustJSON(map[string]any{"name": id, "ip": "127.0.0.1", "port": 1, "version": "test", "groups": groups}),
+	})
+}
+
+func TestElection(t *testing.T) {
+	d := NewDiscovery("test", "test", NopLogger{})
+	d.uuid = "m"
+	defer d.Stop()
+
+	var (
+		mu      sync.Mutex
+		changes []LeaderChange
+	)
+	e := NewElection(d, WithSettleTime(0), WithCheckInterval(time.Hour))
+	e.OnChange(func(c LeaderChange) {
+		mu.Lock()
+		changes = append(changes, c)
+		mu.Unlock()
+	})
+	e.Start()
+
+	e.evaluate()
+	if !e.IsLeader() {
+		t.Fatalf("expected single node to be leader, got %q", e.Leader())
+	}
+
+	announce(d, "a", defaultGroup)
+	e.evaluate()
+	if !e.IsLeader() {
+		t.Fatalf("lower peer must not take leadership, got %q", e.Leader())
+	}
+
+	// 未参与选举的节点即使 UUID 更大也不是候选者
+	announce(d, "zz")
+	announce(d, "zy", "other")
+	e.evaluate()
+	if !e.IsLeader() {
+		t.Fatalf("non-participant must not take leadership, got %q", e.Leader())
+	}
+
+	announce(d, "z", defaultGroup)
+	e.evaluate()
+	if e.IsLeader() || e.Leader() != "z" {
+		t.Fatalf("expected z to be leader, got %q", e.Leader())
+	}
+
+	// 模拟 cleanupDevices 移除过期节点
+	d.mu.Lock()
+	dev := d.devices["z"]
+	delete(d.devices, "z")
+	d.mu.Unlock()
+	d.emit(DeviceLeft, dev)
+	e.evaluate()
+	if !e.IsLeader() {
+		t.Fatalf("expected leadership back after peer expired, got %q", e.Leader())
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(changes) != 3 {
+		t.Fatalf("expected 3 leader changes, got %d: %+v", len(changes), changes)
+	}
+}
+
+func TestElectionHandlerOffReceivePath(t *testing.T) {
+	d := NewDiscovery("test", "test", NopLogger{})
+	d.uuid = "m"
+	defer d.Stop()
+
+	release := make(chan struct{})
+	notified := make(chan LeaderChange, 4)
+	e := NewElection(d, WithSettleTime(0), WithCheckInterval(time.Hour))
+	e.OnChange(func(c LeaderChange) {
+		notified <- c
+		<-release
+	})
+	e.Start()
+
+	// 处理函数阻塞时 announce 仍应立即返回
+	done := make(chan struct{})
+	go func() {
+		announce(d, "z", defaultGroup)
+		announce(d, "y", defaultGroup)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("announce blocked on leader handler")
+	}
+
+	select {
+	case c := <-notified:
+		if c.Leader != "z" {
+			t.Fatalf("expected z to be leader, got %q", c.Leader)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("leader change not delivered")
+	}
+	close(release)
+}
+
+func TestElectionSettle(t *testing.T) {
+	d := NewDiscovery("test", "test", NopLogger{})
+	defer d.Stop()
+
+	e := NewElection(d, WithSettleTime(time.Hour))
+	e.Start()
+	e.evaluate()
+	if e.IsLeader() || e.Leader() != "" {
+		t.Fatalf("expected no leader while settling, got %q", e.Leader())
+	}
+}
@@ -0,0 +1,153 @@
+package discovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Snapshot 是 Discovery 当前状态的只读快照
+type Snapshot struct {
+	UUID     string      `json:"uuid"`
+	Name     string      `json:"name"`
+	Addr     string      `json:"addr"`
+	Version  string      `json:"version"`
+	Devices  []Device    `json:"devices"`
+	Handlers []string    `json:"handlers"`
+	Pending  int         `json:"pending"`
+	Peers    []PeerStats `json:"peers"`
+}
+
+// Snapshot 返回当前设备表、已注册命令与通信统计
+func (d *Discovery) Snapshot() Snapshot {
+	devices := make([]Device, 0)
+	for _, dev := range d.GetDevices() {
+		devices = append(devices, *dev)
+	}
+	return Snapshot{
+		UUID:     d.uuid,
+		Name:     d.name,
+		Addr:     fmt.Sprintf("%s:%d", d.ip, d.port),
+		Version:  d.version,
+		Devices:  devices,
+		Handlers: d.Handlers(),
+		Pending:  d.PendingCount(),
+		Peers:    d.PeerStats(),
+	}
+}
+
+// Handler 返回用于现场调试的 HTTP/JSON 接口：
+//
+//	GET /          完整快照
+//	GET /devices   设备表
+//	GET /handlers  已注册命令
+//	GET /stats     pending 数量与对端统计
+//	GET /events    设备事件 SSE 流
+//
+// 挂载到子路径时请配合 http.StripPrefix 使用。
+func (d *Discovery) Handler() http.Handler {
+	d.hubOnce.Do(func() {
+		d.hub = newEventHub()
+		d.OnDeviceEvent(d.hub.publish)
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, d.Snapshot())
+	})
+	mux.HandleFunc("GET /devices", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, d.Snapshot().Devices)
+	})
+	mux.HandleFunc("GET /handlers", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, d.Handlers())
+	})
+	mux.HandleFunc("GET /stats", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{
+			"pending": d.PendingCount(),
+			"peers":   d.PeerStats(),
+		})
+	})
+	mux.HandleFunc("GET /events", d.serveEvents)
+	return mux
+}
+
+// serveEvents 以 Server-Sent Events 推送设备事件
+func (d *Discovery) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	events, cancel := d.hub.subscribe()
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepalive := time.NewTicker(announceIntv)
+	defer keepalive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-d.ctx.Done():
+			return
+		case <-keepalive.C:
+			_, _ = fmt.Fprint(w, ": keepalive\n\n")
+			flusher.Flush()
+		case evt := <-events:
+			data, err := json.Marshal(evt)
+			if err != nil {
+				continue
+			}
+			_, _ = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", evt.Type, data)
+			flusher.Flush()
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+// eventHub 将设备事件分发给多个 SSE 订阅者，慢订阅者的事件会被丢弃
+type eventHub struct {
+	mu   sync.Mutex
+	subs map[chan DeviceEvent]struct{}
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{subs: make(map[chan DeviceEvent]struct{})}
+}
+
+func (h *eventHub) subscribe() (<-chan DeviceEvent, func()) {
+	ch := make(chan DeviceEvent, 16)
+	h.mu.Lock()
+	h.subs[ch] = struct{}{}
+	h.mu.Unlock()
+	return ch, func() {
+		h.mu.Lock()
+		delete(h.subs, ch)
+		h.mu.Unlock()
+	}
+}
+
+func (h *eventHub) publish(evt DeviceEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs {
+		select {
+		case ch <- evt:
+		default:
+		}
+	}
+}
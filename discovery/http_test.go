@@ -0,0 +1,79 @@
+package discovery
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandlerSnapshot(t *testing.T) {
+	d := NewDiscovery("test", "test", NopLogger{})
+	defer d.Stop()
+	d.RegisterHandler("ping", func(net.Addr, MessageEnvelope) {})
+	announce(d, "peer-1")
+
+	srv := httptest.NewServer(d.Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var snap Snapshot
+	if err := json.NewDecoder(resp.Body).Decode(&snap); err != nil {
+		t.Fatal(err)
+	}
+	if len(snap.Devices) != 1 || snap.Devices[0].UUID != "peer-1" {
+		t.Fatalf("unexpected devices: %+v", snap.Devices)
+	}
+	if len(snap.Handlers) != 1 || snap.Handlers[0] != "ping" {
+		t.Fatalf("unexpected handlers: %+v", snap.Handlers)
+	}
+	if len(snap.Peers) != 1 || snap.Peers[0].MessagesIn != 1 {
+		t.Fatalf("unexpected peer stats: %+v", snap.Peers)
+	}
+}
+
+func TestHandlerEvents(t *testing.T) {
+	d := NewDiscovery("test", "test", NopLogger{})
+	defer d.Stop()
+
+	srv := httptest.NewServer(d.Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	// 响应头已返回，说明订阅已建立
+	announce(d, "peer-2")
+
+	lines := make(chan string)
+	go func() {
+		sc := bufio.NewScanner(resp.Body)
+		for sc.Scan() {
+			lines <- sc.Text()
+		}
+	}()
+
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case line := <-lines:
+			if strings.HasPrefix(line, "data: ") && strings.Contains(line, "peer-2") {
+				return
+			}
+		case <-deadline:
+			t.Fatal("timed out waiting for device event")
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"strings"
+)
+
+// Logger 接口定义了日志方法。
+// 参数采用 log/slog 风格：msg 为固定消息，args 为键值对（如 "uuid", id），
+// 因此 *slog.Logger 可直接作为 Logger 使用。
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// StdLogger 是 Logger 接口的标准实现，通过 log.Printf 输出 key=value 格式。
+// 默认不输出 Debug 级别日志，设置 Verbose 后输出。
+type StdLogger struct {
+	Verbose bool
+}
+
+func (l *StdLogger) Debug(msg string, args ...any) {
+	if l.Verbose {
+		log.Print("[DEBUG] " + formatArgs(msg, args))
+	}
+}
+
+func (l *StdLogger) Info(msg string, args ...any) {
+	log.Print("[INFO] " + formatArgs(msg, args))
+}
+
+func (l *StdLogger) Error(msg string, args ...any) {
+	log.Print("[ERROR] " + formatArgs(msg, args))
+}
+
+// NopLogger 丢弃所有日志
+type NopLogger struct{}
+
+func (NopLogger) Debug(string, ...any) {}
+func (NopLogger) Info(string, ...any)  {}
+func (NopLogger) Error(string, ...any) {}
+
+// SlogLogger 将 *slog.Logger 适配为 Logger，并附加 component=discovery 字段
+type SlogLogger struct {
+	l *slog.Logger
+}
+
+// NewSlogLogger 创建 slog 适配器，l 为 nil 时使用 slog.Default()
+func NewSlogLogger(l *slog.Logger) *SlogLogger {
+	if l == nil {
+		l = slog.Default()
+	}
+	return &SlogLogger{l: l.With("component", "discovery")}
+}
+
+func (s *SlogLogger) Debug(msg string, args ...any) {
+	s.l.Log(context.Background(), slog.LevelDebug, msg, args...)
+}
+
+func (s *SlogLogger) Info(msg string, args ...any) {
+	s.l.Log(context.Background(), slog.LevelInfo, msg, args...)
+}
+
+func (s *SlogLogger) Error(msg string, args ...any) {
+	s.l.Log(context.Background(), slog.LevelError, msg, args...)
+}
+
+// formatArgs 将键值对拼接为 "msg key=value ..." 形式
+func formatArgs(msg string, args []any) string {
+	var b strings.Builder
+	b.WriteString(msg)
+	for i := 0; i < len(args); i += 2 {
+		if i+1 >= len(args) {
+			fmt.Fprintf(&b, " !BADKEY=%v", args[i])
+			break
+		}
+		fmt.Fprintf(&b, " %v=%v", args[i], args[i+1])
+	}
+	return b.String()
+}
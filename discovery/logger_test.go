@@ -0,0 +1,32 @@
+package discovery
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestFormatArgs(t *testing.T) {
+	got := formatArgs("发现新设备", []any{"uuid", "abc", "port", 9999, "dangling"})
+	want := "发现新设备 uuid=abc port=9999 !BADKEY=dangling"
+	if got != want {
+		t.Fatalf("formatArgs() = %q, want %q", got, want)
+	}
+}
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewSlogLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})))
+
+	l.Debug("未注册命令处理器", "command", "ping")
+	l.Info("发现新设备", "uuid", "abc")
+
+	out := buf.String()
+	if strings.Contains(out, "ping") {
+		t.Fatalf("debug message should be filtered: %s", out)
+	}
+	if !strings.Contains(out, "component=discovery") || !strings.Contains(out, "uuid=abc") {
+		t.Fatalf("missing structured fields: %s", out)
+	}
+}
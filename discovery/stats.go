@@ -0,0 +1,120 @@
+package discovery
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"time"
+)
+
+// PeerStats 记录与单个对端的通信统计
+type PeerStats struct {
+	UUID        string        `json:"uuid,omitempty"`
+	Addr        string        `json:"addr,omitempty"`
+	LastSeen    time.Time     `json:"lastSeen"`
+	MessagesIn  uint64        `json:"messagesIn"`
+	MessagesOut uint64        `json:"messagesOut"`
+	RTT         time.Duration `json:"rtt"` // 最近一次 RequestResponse 的往返时延
+}
+
+// PeerStats 返回所有对端的通信统计副本
+func (d *Discovery) PeerStats() []PeerStats {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	stats := make([]PeerStats, 0, len(d.stats))
+	for _, s := range d.stats {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].UUID+stats[i].Addr < stats[j].UUID+stats[j].Addr
+	})
+	return stats
+}
+
+// PendingCount 返回等待响应的请求数量
+func (d *Discovery) PendingCount() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return len(d.pending)
+}
+
+// Handlers 返回已注册的命令名称
+func (d *Discovery) Handlers() []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	cmds := make([]string, 0, len(d.handlers))
+	for cmd := range d.handlers {
+		cmds = append(cmds, cmd)
+	}
+	sort.Strings(cmds)
+	return cmds
+}
+
+// peerLocked 返回 key 对应的统计项，不存在时创建，调用方须持有 d.mu
+func (d *Discovery) peerLocked(key string) *PeerStats {
+	s, ok := d.stats[key]
+	if !ok {
+		s = &PeerStats{}
+		d.stats[key] = s
+	}
+	return s
+}
+
+// pruneStatsLocked 删除不属于在线设备且超时未收到消息的统计项，
+// 仅按地址记录发送的项没有 LastSeen，也会一并删除。调用方须持有 d.mu
+func (d *Discovery) pruneStatsLocked(now time.Time) {
+	for key, s := range d.stats {
+		if _, ok := d.devices[key]; ok {
+			continue
+		}
+		if now.Sub(s.LastSeen) > timeout {
+			delete(d.stats, key)
+		}
+	}
+}
+
+func (d *Discovery) recordIn(uuid string, from net.Addr) {
+	if uuid == "" {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	s := d.peerLocked(uuid)
+	s.UUID = uuid
+	if from != nil {
+		s.Addr = from.String()
+	}
+	s.LastSeen = time.Now()
+	s.MessagesIn++
+}
+
+// recordOut 按目标地址记录发送，能匹配到已知设备时归入该设备的 UUID
+func (d *Discovery) recordOut(addr string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	key := addr
+	for _, dev := range d.devices {
+		if fmt.Sprintf("%s:%d", dev.IP, dev.Port) == addr {
+			key = dev.UUID
+			break
+		}
+	}
+	s := d.peerLocked(key)
+	if key != addr {
+		s.UUID = key
+	} else if s.Addr == "" {
+		s.Addr = addr
+	}
+	s.MessagesOut++
+}
+
+func (d *Discovery) recordRTT(uuid string, rtt time.Duration) {
+	if uuid == "" {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	s := d.peerLocked(uuid)
+	s.UUID = uuid
+	s.RTT = rtt
+}
@@ -0,0 +1,28 @@
+package discovery
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpireDevicesPrunesStats(t *testing.T) {
+	d := NewDiscovery("test", "test", NopLogger{})
+	defer d.Stop()
+
+	announce(d, "peer-1")
+	d.recordOut("127.0.0.1:1")    // 归入 peer-1
+	d.recordOut("192.0.2.1:9999") // 未知地址
+
+	d.expireDevices(time.Now())
+	if stats := d.PeerStats(); len(stats) != 1 || stats[0].UUID != "peer-1" || stats[0].MessagesOut != 1 {
+		t.Fatalf("expected only live peer stats, got %+v", stats)
+	}
+
+	d.expireDevices(time.Now().Add(timeout + time.Second))
+	if devs := d.GetDevices(); len(devs) != 0 {
+		t.Fatalf("expected device expired, got %+v", devs)
+	}
+	if stats := d.PeerStats(); len(stats) != 0 {
+		t.Fatalf("expected stats pruned, got %+v", stats)
+	}
+}
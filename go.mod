@@ -11,6 +11,7 @@ require (
 	github.com/google/uuid v1.6.0
 	github.com/package-register/go-genius v0.1.12
 	github.com/sirupsen/logrus v1.9.3
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,95 @@
+package release
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/package-register/go-toolkit/build"
+)
+
+// ErrNoNotes is returned by NotesFromChangelog when the changelog has neither
+// a section for the version nor an Unreleased section.
+var ErrNoNotes = errors.New("release: no changelog section")
+
+// Assets returns the files to upload for build artifacts: every archive, or
+// binary when not archived, followed by the checksums file of dir if present.
+func Assets(dir string, artifacts []build.Artifact) []string {
+	files := make([]string, 0, len(artifacts)+1)
+	for _, a := range artifacts {
+		files = append(files, a.File())
+	}
+	checksums := filepath.Join(dir, build.ChecksumsFile)
+	if _, err := os.Stat(checksums); err == nil {
+		files = append(files, checksums)
+	}
+	return files
+}
+
+// AssetsFromManifest reads the build manifest of dir and returns its Assets,
+// with the artifact paths resolved against dir.
+func AssetsFromManifest(dir string) (*build.Manifest, []string, error) {
+	m, err := build.LoadManifest(filepath.Join(dir, build.ManifestFile))
+	if err != nil {
+		return nil, nil, err
+	}
+	return m, Assets(dir, m.Artifacts), nil
+}
+
+// NotesFromChangelog returns the body of the changelog section of version,
+// matching headings like "## [v1.2.0]", "## [1.2.0] - 2024-01-02" or
+// "## v1.2.0". It falls back to the "## [Unreleased]" section.
+func NotesFromChangelog(path, version string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("%w: %s does not exist", ErrNoNotes, path)
+		}
+		return "", err
+	}
+	defer f.Close()
+
+	sections := make(map[string][]string)
+	var current string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "## ") {
+			current = sectionVersion(line[3:])
+			continue
+		}
+		if current != "" {
+			sections[current] = append(sections[current], line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	for _, key := range []string{strings.TrimPrefix(version, "v"), "unreleased"} {
+		if lines, ok := sections[key]; ok {
+			if notes := strings.TrimSpace(strings.Join(lines, "\n")); notes != "" {
+				return notes, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%w for %s", ErrNoNotes, version)
+}
+
+// sectionVersion normalizes a "## " heading to a lookup key: the bracketed or
+// first word, without a leading "v", lower-cased.
+func sectionVersion(heading string) string {
+	heading = strings.TrimSpace(heading)
+	if strings.HasPrefix(heading, "[") {
+		if i := strings.IndexByte(heading, ']'); i > 0 {
+			heading = heading[1:i]
+		}
+	} else if fields := strings.Fields(heading); len(fields) > 0 {
+		heading = fields[0]
+	}
+	return strings.ToLower(strings.TrimPrefix(heading, "v"))
+}
@@ -0,0 +1,339 @@
+// Package release publishes build artifacts to a GitHub-compatible release API.
+package release
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is the GitHub REST API endpoint.
+const DefaultBaseURL = "https://api.github.com"
+
+// ErrNotFound is returned when a release or asset does not exist.
+var ErrNotFound = errors.New("release: not found")
+
+// APIError is a non-2xx response of the release API. A 404 unwraps to ErrNotFound.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("release api: %d %s", e.StatusCode, e.Message)
+}
+
+func (e *APIError) Unwrap() error {
+	if e.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Config configures a Client.
+type Config struct {
+	BaseURL    string // API endpoint; defaults to DefaultBaseURL.
+	Token      string // Sent as a bearer token when set.
+	Owner      string
+	Repo       string
+	HTTPClient *http.Client // Defaults to a client with a 5 minute timeout.
+}
+
+// Client talks to the releases endpoints of one repository.
+type Client struct {
+	base  string
+	token string
+	owner string
+	repo  string
+	http  *http.Client
+}
+
+// New creates a Client.
+func New(cfg Config) (*Client, error) {
+	if cfg.Owner == "" || cfg.Repo == "" {
+		return nil, errors.New("release: owner and repo are required")
+	}
+	c := &Client{
+		base:  strings.TrimRight(cfg.BaseURL, "/"),
+		token: cfg.Token,
+		owner: cfg.Owner,
+		repo:  cfg.Repo,
+		http:  cfg.HTTPClient,
+	}
+	if c.base == "" {
+		c.base = DefaultBaseURL
+	}
+	if c.http == nil {
+		c.http = &http.Client{Timeout: 5 * time.Minute}
+	}
+	return c, nil
+}
+
+// Release is a repository release.
+type Release struct {
+	ID         int64   `json:"id"`
+	TagName    string  `json:"tag_name"`
+	Name       string  `json:"name"`
+	Body       string  `json:"body"`
+	Draft      bool    `json:"draft"`
+	Prerelease bool    `json:"prerelease"`
+	HTMLURL    string  `json:"html_url"`
+	UploadURL  string  `json:"upload_url"`
+	Assets     []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release.
+type Asset struct {
+	ID                 int64  `json:"id"`
+	Name               string `json:"name"`
+	Size               int64  `json:"size"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+// ReleaseInput is the body of a create or update request.
+type ReleaseInput struct {
+	TagName         string `json:"tag_name"`
+	TargetCommitish string `json:"target_commitish,omitempty"`
+	Name            string `json:"name,omitempty"`
+	Body            string `json:"body,omitempty"`
+	Draft           bool   `json:"draft"`
+	Prerelease      bool   `json:"prerelease"`
+}
+
+// ReleaseByTag returns the release of a tag, or an error wrapping ErrNotFound.
+func (c *Client) ReleaseByTag(ctx context.Context, tag string) (*Release, error) {
+	var rel Release
+	if err := c.do(ctx, http.MethodGet, c.repoURL("releases/tags/"+url.PathEscape(tag)), nil, &rel); err != nil {
+		return nil, err
+	}
+	return &rel, nil
+}
+
+// draftByTag returns the draft release of a tag, which the tag endpoint does
+// not return, by listing the releases of the repository.
+func (c *Client) draftByTag(ctx context.Context, tag string) (*Release, error) {
+	for page := 1; ; page++ {
+		var rels []Release
+		if err := c.do(ctx, http.MethodGet, c.repoURL(fmt.Sprintf("releases?per_page=100&page=%d", page)), nil, &rels); err != nil {
+			return nil, err
+		}
+		for i := range rels {
+			if rels[i].Draft && rels[i].TagName == tag {
+				return &rels[i], nil
+			}
+		}
+		if len(rels) < 100 {
+			return nil, &APIError{StatusCode: http.StatusNotFound, Message: "release " + tag + " not found"}
+		}
+	}
+}
+
+// CreateRelease creates a release.
+func (c *Client) CreateRelease(ctx context.Context, in ReleaseInput) (*Release, error) {
+	var rel Release
+	if err := c.do(ctx, http.MethodPost, c.repoURL("releases"), in, &rel); err != nil {
+		return nil, err
+	}
+	return &rel, nil
+}
+
+// UpdateRelease updates the release with the given ID.
+func (c *Client) UpdateRelease(ctx context.Context, id int64, in ReleaseInput) (*Release, error) {
+	var rel Release
+	if err := c.do(ctx, http.MethodPatch, c.repoURL(fmt.Sprintf("releases/%d", id)), in, &rel); err != nil {
+		return nil, err
+	}
+	return &rel, nil
+}
+
+// DeleteAsset deletes a release asset.
+func (c *Client) DeleteAsset(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, c.repoURL(fmt.Sprintf("releases/assets/%d", id)), nil, nil)
+}
+
+// UploadAsset uploads a file to a release under its base name, replacing an
+// existing asset of the same name.
+func (c *Client) UploadAsset(ctx context.Context, rel *Release, path string) (*Asset, error) {
+	name := filepath.Base(path)
+	for _, a := range rel.Assets {
+		if a.Name == name {
+			if err := c.DeleteAsset(ctx, a.ID); err != nil && !errors.Is(err, ErrNotFound) {
+				return nil, fmt.Errorf("failed to replace asset %s: %w", name, err)
+			}
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	// upload_url is a URI template such as ".../assets{?name,label}".
+	endpoint := rel.UploadURL
+	if i := strings.IndexByte(endpoint, '{'); i >= 0 {
+		endpoint = endpoint[:i]
+	}
+	if endpoint == "" {
+		return nil, fmt.Errorf("release %d has no upload url", rel.ID)
+	}
+	endpoint += "?name=" + url.QueryEscape(name)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, f)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = info.Size()
+	contentType := mime.TypeByExtension(filepath.Ext(name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	var asset Asset
+	if err := c.send(req, &asset); err != nil {
+		return nil, fmt.Errorf("failed to upload %s: %w", name, err)
+	}
+	return &asset, nil
+}
+
+// PublishOptions describes a release to publish.
+type PublishOptions struct {
+	Tag        string
+	Name       string // Defaults to Tag.
+	Commitish  string // Target of the tag when the release creates it.
+	Notes      string
+	Draft      bool
+	Prerelease bool
+	Assets     []string // Files to upload, see Assets.
+}
+
+// Publish creates the release of opts.Tag, or updates it if it already
+// exists, including as a draft, and uploads the assets.
+func (c *Client) Publish(ctx context.Context, opts PublishOptions) (*Release, error) {
+	if opts.Tag == "" {
+		return nil, errors.New("release: tag is required")
+	}
+	in := ReleaseInput{
+		TagName:         opts.Tag,
+		TargetCommitish: opts.Commitish,
+		Name:            opts.Name,
+		Body:            opts.Notes,
+		Draft:           opts.Draft,
+		Prerelease:      opts.Prerelease,
+	}
+	if in.Name == "" {
+		in.Name = opts.Tag
+	}
+
+	rel, err := c.ReleaseByTag(ctx, opts.Tag)
+	if errors.Is(err, ErrNotFound) {
+		rel, err = c.draftByTag(ctx, opts.Tag)
+	}
+	switch {
+	case err == nil:
+		updated, err := c.UpdateRelease(ctx, rel.ID, in)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update release %s: %w", opts.Tag, err)
+		}
+		// Keep the known assets so that re-uploads replace them.
+		if updated.Assets == nil {
+			updated.Assets = rel.Assets
+		}
+		rel = updated
+	case errors.Is(err, ErrNotFound):
+		if rel, err = c.CreateRelease(ctx, in); err != nil {
+			return nil, fmt.Errorf("failed to create release %s: %w", opts.Tag, err)
+		}
+	default:
+		return nil, err
+	}
+
+	for _, path := range opts.Assets {
+		asset, err := c.UploadAsset(ctx, rel, path)
+		if err != nil {
+			return rel, err
+		}
+		rel.Assets = replaceAsset(rel.Assets, *asset)
+	}
+	return rel, nil
+}
+
+func replaceAsset(assets []Asset, a Asset) []Asset {
+	out := assets[:0]
+	for _, old := range assets {
+		if old.Name != a.Name {
+			out = append(out, old)
+		}
+	}
+	return append(out, a)
+}
+
+func (c *Client) repoURL(path string) string {
+	return fmt.Sprintf("%s/repos/%s/%s/%s", c.base, url.PathEscape(c.owner), url.PathEscape(c.repo), path)
+}
+
+func (c *Client) do(ctx context.Context, method, endpoint string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return c.send(req, out)
+}
+
+func (c *Client) send(req *http.Request, out any) error {
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var e struct {
+			Message string `json:"message"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		if json.Unmarshal(data, &e) != nil || e.Message == "" {
+			e.Message = strings.TrimSpace(string(data))
+		}
+		return &APIError{StatusCode: resp.StatusCode, Message: e.Message}
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,314 @@
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/package-register/go-toolkit/build"
+)
+
+// fakeAPI is an in-memory subset of the GitHub releases API for one repository.
+type fakeAPI struct {
+	mu       sync.Mutex
+	nextID   int64
+	created  int
+	releases map[string]*Release // By tag.
+	uploads  map[string][]byte   // By asset name.
+	server   *httptest.Server
+}
+
+func newFakeAPI(t *testing.T) *fakeAPI {
+	f := &fakeAPI{releases: make(map[string]*Release), uploads: make(map[string][]byte)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/o/r/releases/tags/{tag}", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		// Like GitHub, the tag endpoint does not return drafts.
+		rel, ok := f.releases[r.PathValue("tag")]
+		if !ok || rel.Draft {
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(rel)
+	})
+	mux.HandleFunc("GET /repos/o/r/releases", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		rels := []*Release{}
+		if r.URL.Query().Get("page") == "1" {
+			for _, rel := range f.releases {
+				rels = append(rels, rel)
+			}
+		}
+		json.NewEncoder(w).Encode(rels)
+	})
+	mux.HandleFunc("POST /repos/o/r/releases", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, `{"message":"Bad credentials"}`, http.StatusUnauthorized)
+			return
+		}
+		var in ReleaseInput
+		json.NewDecoder(r.Body).Decode(&in)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.nextID++
+		f.created++
+		rel := &Release{
+			ID:        f.nextID,
+			TagName:   in.TagName,
+			Name:      in.Name,
+			Body:      in.Body,
+			Draft:     in.Draft,
+			UploadURL: fmt.Sprintf("%s/uploads/%d/assets{?name,label}", f.server.URL, f.nextID),
+		}
+		f.releases[in.TagName] = rel
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(rel)
+	})
+	mux.HandleFunc("PATCH /repos/o/r/releases/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var in ReleaseInput
+		json.NewDecoder(r.Body).Decode(&in)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		rel := f.byID(r.PathValue("id"))
+		if rel == nil {
+			http.NotFound(w, r)
+			return
+		}
+		rel.Name, rel.Body, rel.Draft = in.Name, in.Body, in.Draft
+		json.NewEncoder(w).Encode(rel)
+	})
+	mux.HandleFunc("DELETE /repos/o/r/releases/assets/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		for _, rel := range f.releases {
+			for i, a := range rel.Assets {
+				if a.ID == id {
+					rel.Assets = append(rel.Assets[:i], rel.Assets[i+1:]...)
+					delete(f.uploads, a.Name)
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+			}
+		}
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("POST /uploads/{id}/assets", func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		name := r.URL.Query().Get("name")
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		rel := f.byID(r.PathValue("id"))
+		if rel == nil {
+			http.NotFound(w, r)
+			return
+		}
+		for _, a := range rel.Assets {
+			if a.Name == name {
+				http.Error(w, `{"message":"already_exists"}`, http.StatusUnprocessableEntity)
+				return
+			}
+		}
+		f.nextID++
+		a := Asset{ID: f.nextID, Name: name, Size: int64(len(data))}
+		rel.Assets = append(rel.Assets, a)
+		f.uploads[name] = data
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(a)
+	})
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (f *fakeAPI) byID(id string) *Release {
+	for _, rel := range f.releases {
+		if strconv.FormatInt(rel.ID, 10) == id {
+			return rel
+		}
+	}
+	return nil
+}
+
+func TestPublishCreatesThenUpdates(t *testing.T) {
+	api := newFakeAPI(t)
+	c, err := New(Config{BaseURL: api.server.URL, Token: "secret", Owner: "o", Repo: "r"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "app-v1.0.0-linux-amd64.tar.gz")
+	checksums := filepath.Join(dir, "checksums.txt")
+	writeFile(t, archive, "v1")
+	writeFile(t, checksums, "sum  app-v1.0.0-linux-amd64.tar.gz\n")
+
+	ctx := context.Background()
+	opts := PublishOptions{Tag: "v1.0.0", Notes: "first", Assets: []string{archive, checksums}}
+	rel, err := c.Publish(ctx, opts)
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if rel.Name != "v1.0.0" || len(rel.Assets) != 2 {
+		t.Fatalf("release = %+v", rel)
+	}
+
+	// Publishing again updates the notes and replaces the assets.
+	writeFile(t, archive, "v1 rebuilt")
+	opts.Notes = "second"
+	if _, err := c.Publish(ctx, opts); err != nil {
+		t.Fatalf("Publish again: %v", err)
+	}
+	got, err := c.ReleaseByTag(ctx, "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Body != "second" || len(got.Assets) != 2 {
+		t.Fatalf("release = %+v", got)
+	}
+	if data := string(api.uploads[filepath.Base(archive)]); data != "v1 rebuilt" {
+		t.Fatalf("asset = %q", data)
+	}
+}
+
+func TestPublishUpdatesDraft(t *testing.T) {
+	api := newFakeAPI(t)
+	c, _ := New(Config{BaseURL: api.server.URL, Token: "secret", Owner: "o", Repo: "r"})
+
+	ctx := context.Background()
+	opts := PublishOptions{Tag: "v2.0.0", Notes: "draft", Draft: true}
+	first, err := c.Publish(ctx, opts)
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if _, err := c.ReleaseByTag(ctx, "v2.0.0"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("tag endpoint returned a draft: %v", err)
+	}
+
+	opts.Notes = "final"
+	opts.Draft = false
+	rel, err := c.Publish(ctx, opts)
+	if err != nil {
+		t.Fatalf("Publish again: %v", err)
+	}
+	if api.created != 1 || rel.ID != first.ID || rel.Body != "final" || rel.Draft {
+		t.Fatalf("created = %d, release = %+v", api.created, rel)
+	}
+}
+
+func TestPublishFromRelocatedManifest(t *testing.T) {
+	api := newFakeAPI(t)
+	c, _ := New(Config{BaseURL: api.server.URL, Token: "secret", Owner: "o", Repo: "r"})
+
+	dir := filepath.Join(t.TempDir(), "dist")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	m := build.Manifest{Name: "app", Artifacts: []build.Artifact{
+		{Platform: build.Platform{OS: "linux", Arch: "amd64"}, Binary: "app_linux_amd64", Archive: "app_linux_amd64.tar.gz"},
+		{Platform: build.Platform{OS: "darwin", Arch: "arm64"}, Binary: "app_darwin_arm64"},
+	}}
+	files := map[string]string{
+		"app_linux_amd64":        "elf",
+		"app_linux_amd64.tar.gz": "tgz",
+		"app_darwin_arm64":       "macho",
+		build.ChecksumsFile:      "sums\n",
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files[build.ManifestFile] = string(data)
+	for name, content := range files {
+		writeFile(t, filepath.Join(dir, name), content)
+	}
+
+	// The manifest is read from another workspace than the one it was built in.
+	moved := filepath.Join(t.TempDir(), "downloaded")
+	if err := os.Rename(dir, moved); err != nil {
+		t.Fatal(err)
+	}
+	_, assets, err := AssetsFromManifest(moved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		filepath.Join(moved, "app_linux_amd64.tar.gz"),
+		filepath.Join(moved, "app_darwin_arm64"),
+		filepath.Join(moved, build.ChecksumsFile),
+	}
+	if !slices.Equal(assets, want) {
+		t.Fatalf("assets = %v, want %v", assets, want)
+	}
+
+	if _, err := c.Publish(context.Background(), PublishOptions{Tag: "v1.0.0", Assets: assets}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	for _, name := range []string{"app_linux_amd64.tar.gz", "app_darwin_arm64", build.ChecksumsFile} {
+		if string(api.uploads[name]) != files[name] {
+			t.Errorf("upload %s = %q", name, api.uploads[name])
+		}
+	}
+}
+
+func TestReleaseByTagNotFound(t *testing.T) {
+	api := newFakeAPI(t)
+	c, _ := New(Config{BaseURL: api.server.URL, Owner: "o", Repo: "r"})
+
+	_, err := c.ReleaseByTag(context.Background(), "v9")
+	var apiErr *APIError
+	if !errors.Is(err, ErrNotFound) || !errors.As(err, &apiErr) || apiErr.Message != "Not Found" {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := c.CreateRelease(context.Background(), ReleaseInput{TagName: "v9"}); err == nil {
+		t.Fatal("expected unauthorized error")
+	}
+}
+
+func TestNotesFromChangelog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	writeFile(t, path, `# CHANGELOG
+
+## [Unreleased]
+
+- pending
+
+## [1.2.0] - 2024-01-02
+
+### Added
+- thing
+
+## v1.1.0
+- older
+`)
+
+	for version, want := range map[string]string{
+		"v1.2.0": "### Added\n- thing",
+		"1.1.0":  "- older",
+		"v2.0.0": "- pending",
+	} {
+		got, err := NotesFromChangelog(path, version)
+		if err != nil || got != want {
+			t.Errorf("NotesFromChangelog(%s) = %q, %v; want %q", version, got, err, want)
+		}
+	}
+}
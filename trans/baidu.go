@@ -0,0 +1,155 @@
+package translator
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BaiduConfig 百度通用翻译 API 配置
+type BaiduConfig struct {
+	AppID      string
+	Secret     string
+	Endpoint   string // 默认 https://fanyi-api.baidu.com/api/trans/vip/translate
+	FromLang   string // 默认 auto
+	ToLang     string // 默认 en
+	HTTPClient *http.Client
+	Policy     *Policy // 限速、重试与熔断策略，为空时不启用
+}
+
+type baidu struct {
+	client   *http.Client
+	guard    *guard
+	endpoint string
+	appid    string
+	secret   string
+	fromLang string
+	toLang   string
+}
+
+// NewBaidu 创建百度翻译服务
+func NewBaidu(config BaiduConfig) Provider {
+	b := &baidu{
+		client:   config.HTTPClient,
+		endpoint: config.Endpoint,
+		appid:    config.AppID,
+		secret:   config.Secret,
+		fromLang: config.FromLang,
+		toLang:   config.ToLang,
+	}
+	if config.Policy != nil {
+		b.guard = newGuard(*config.Policy)
+	}
+	if b.client == nil {
+		b.client = &http.Client{Timeout: defaultTimeout}
+	}
+	if b.endpoint == "" {
+		b.endpoint = "https://fanyi-api.baidu.com/api/trans/vip/translate"
+	}
+	if b.fromLang == "" {
+		b.fromLang = "auto"
+	}
+	if b.toLang == "" {
+		b.toLang = "en"
+	}
+	return b
+}
+
+// Name 返回服务提供方名称
+func (b *baidu) Name() string {
+	return "baidu"
+}
+
+// baiduResponse 百度通用翻译响应
+type baiduResponse struct {
+	From        string `json:"from"`
+	To          string `json:"to"`
+	ErrorCode   string `json:"error_code"`
+	ErrorMsg    string `json:"error_msg"`
+	TransResult []struct {
+		Src string `json:"src"`
+		Dst string `json:"dst"`
+	} `json:"trans_result"`
+}
+
+// TranslateContext 翻译单条文本
+func (b *baidu) TranslateContext(ctx context.Context, req Request) (*TranslationResult, error) {
+	if req.From == "" {
+		req.From = b.fromLang
+	}
+	if req.To == "" {
+		req.To = b.toLang
+	}
+
+	var resp *baiduResponse
+	err := b.guard.Do(ctx, func() error {
+		var err error
+		resp, err = b.send(ctx, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.TransResult) == 0 {
+		return nil, fmt.Errorf("no translation result found")
+	}
+
+	// 多行文本会按行返回多条结果
+	src := make([]string, len(resp.TransResult))
+	dst := make([]string, len(resp.TransResult))
+	for i, r := range resp.TransResult {
+		src[i], dst[i] = r.Src, r.Dst
+	}
+	return &TranslationResult{
+		Source: strings.Join(src, "\n"),
+		Target: strings.Join(dst, "\n"),
+		From:   resp.From,
+		To:     resp.To,
+	}, nil
+}
+
+// send 签名并发送一次请求，每次调用生成新的 salt
+func (b *baidu) send(ctx context.Context, req Request) (*baiduResponse, error) {
+	salt := strconv.FormatInt(time.Now().UnixNano(), 10)
+	sum := md5.Sum([]byte(b.appid + req.Text + salt + b.secret))
+	form := url.Values{
+		"q":     {req.Text},
+		"from":  {req.From},
+		"to":    {req.To},
+		"appid": {b.appid},
+		"salt":  {salt},
+		"sign":  {hex.EncodeToString(sum[:])},
+	}
+
+	request, err := http.NewRequestWithContext(ctx, "POST", b.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("create request failed: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var resp baiduResponse
+	if err := doJSON(b.client, request, b.Name(), &resp); err != nil {
+		return nil, err
+	}
+	if resp.ErrorCode != "" && resp.ErrorCode != "52000" {
+		return nil, &APIError{
+			Provider:   b.Name(),
+			HTTPStatus: http.StatusOK,
+			Code:       resp.ErrorCode,
+			Message:    resp.ErrorMsg,
+			Kind:       baiduErrorKinds[resp.ErrorCode],
+		}
+	}
+	return &resp, nil
+}
+
+// TranslateBatch 逐条翻译，结果与 reqs 一一对应
+func (b *baidu) TranslateBatch(ctx context.Context, reqs []Request) ([]*TranslationResult, error) {
+	return translateEach(ctx, b, reqs)
+}
@@ -0,0 +1,74 @@
+package translator
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestBaidu(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		q, salt := r.PostForm.Get("q"), r.PostForm.Get("salt")
+		sum := md5.Sum([]byte("appid" + q + salt + "secret"))
+		if r.PostForm.Get("appid") != "appid" || salt == "" || r.PostForm.Get("sign") != hex.EncodeToString(sum[:]) {
+			t.Errorf("bad signature: %v", r.PostForm)
+		}
+		if r.PostForm.Get("from") != "zh" || r.PostForm.Get("to") != "en" {
+			t.Errorf("languages = %s -> %s", r.PostForm.Get("from"), r.PostForm.Get("to"))
+		}
+
+		resp := map[string]any{"from": "zh", "to": "en", "error_code": "52000"}
+		var results []map[string]string
+		for _, line := range strings.Split(q, "\n") {
+			results = append(results, map[string]string{"src": line, "dst": "<" + line + ">"})
+		}
+		resp["trans_result"] = results
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	p := NewBaidu(BaiduConfig{AppID: "appid", Secret: "secret", Endpoint: srv.URL, FromLang: "zh"})
+	ctx := context.Background()
+
+	res, err := p.TranslateContext(ctx, Request{Text: "一\n二"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Target != "<一>\n<二>" || res.From != "zh" || res.To != "en" {
+		t.Fatalf("result = %+v", res)
+	}
+
+	results, err := p.TranslateBatch(ctx, []Request{{Text: "早"}, {Text: "晚"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[1].Target != "<晚>" || calls.Load() != 3 {
+		t.Fatalf("batch = %+v after %d calls", results, calls.Load())
+	}
+}
+
+func TestBaiduErrorCode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error_code":"54003","error_msg":"Invalid Access Limit"}`))
+	}))
+	defer srv.Close()
+
+	p := NewBaidu(BaiduConfig{AppID: "appid", Secret: "secret", Endpoint: srv.URL})
+	_, err := p.TranslateContext(context.Background(), Request{Text: "你好"})
+	var apiErr *APIError
+	if !errors.Is(err, ErrRateLimited) || !errors.As(err, &apiErr) || apiErr.Code != "54003" {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -0,0 +1,135 @@
+package translator
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DeepLConfig DeepL 及兼容 API 配置
+type DeepLConfig struct {
+	AuthKey    string
+	BaseURL    string // 默认 https://api-free.deepl.com，兼容服务可替换
+	FromLang   string // 为空时由服务自动识别
+	ToLang     string // 默认 EN
+	HTTPClient *http.Client
+	Policy     *Policy // 限速、重试与熔断策略，为空时不启用
+}
+
+// DeepL 单次请求最多 50 条 text，请求体不超过 128 KiB
+const (
+	deeplMaxTexts = 50
+	deeplMaxBytes = 120 * 1024 // 为 JSON 转义与其它字段预留余量
+)
+
+type deepl struct {
+	client   *http.Client
+	guard    *guard
+	baseURL  string
+	authKey  string
+	fromLang string
+	toLang   string
+}
+
+// NewDeepL 创建 DeepL（或兼容 API）翻译服务
+func NewDeepL(config DeepLConfig) Provider {
+	d := &deepl{
+		client:   config.HTTPClient,
+		baseURL:  strings.TrimSuffix(config.BaseURL, "/"),
+		authKey:  config.AuthKey,
+		fromLang: config.FromLang,
+		toLang:   config.ToLang,
+	}
+	if config.Policy != nil {
+		d.guard = newGuard(*config.Policy)
+	}
+	if d.client == nil {
+		d.client = &http.Client{Timeout: defaultTimeout}
+	}
+	if d.baseURL == "" {
+		d.baseURL = "https://api-free.deepl.com"
+	}
+	if d.toLang == "" {
+		d.toLang = "EN"
+	}
+	return d
+}
+
+// Name 返回服务提供方名称
+func (d *deepl) Name() string {
+	return "deepl"
+}
+
+// TranslateContext 翻译单条文本
+func (d *deepl) TranslateContext(ctx context.Context, req Request) (*TranslationResult, error) {
+	results, err := d.TranslateBatch(ctx, []Request{req})
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+// TranslateBatch 语言对一致时按 DeepL 的条数与大小限制分组请求，否则逐条翻译
+func (d *deepl) TranslateBatch(ctx context.Context, reqs []Request) ([]*TranslationResult, error) {
+	if len(reqs) == 0 {
+		return nil, nil
+	}
+	if !sameLanguages(reqs) {
+		return translateEach(ctx, d, reqs)
+	}
+	return translateChunks(reqs, deeplMaxTexts, deeplMaxBytes, func(chunk []Request) ([]*TranslationResult, error) {
+		return d.translate(ctx, chunk)
+	})
+}
+
+// translate 以一次请求翻译同一语言对的文本
+func (d *deepl) translate(ctx context.Context, reqs []Request) ([]*TranslationResult, error) {
+	from, to := reqs[0].From, reqs[0].To
+	if from == "" {
+		from = d.fromLang
+	}
+	if to == "" {
+		to = d.toLang
+	}
+
+	texts := make([]string, len(reqs))
+	for i, req := range reqs {
+		texts[i] = req.Text
+	}
+	body := map[string]any{
+		"text":        texts,
+		"target_lang": strings.ToUpper(to),
+	}
+	if from != "" {
+		body["source_lang"] = strings.ToUpper(from)
+	}
+
+	var resp struct {
+		Translations []struct {
+			DetectedSourceLanguage string `json:"detected_source_language"`
+			Text                   string `json:"text"`
+		} `json:"translations"`
+	}
+	header := http.Header{"Authorization": {"DeepL-Auth-Key " + d.authKey}}
+	err := d.guard.Do(ctx, func() error {
+		return postJSON(ctx, d.client, d.Name(), d.baseURL+"/v2/translate", header, body, &resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Translations) != len(reqs) {
+		return nil, fmt.Errorf("expected %d translations, got %d", len(reqs), len(resp.Translations))
+	}
+
+	results := make([]*TranslationResult, len(reqs))
+	for i, tr := range resp.Translations {
+		results[i] = &TranslationResult{
+			Source: reqs[i].Text,
+			Target: tr.Text,
+			From:   tr.DetectedSourceLanguage,
+			To:     to,
+		}
+	}
+	return results, nil
+}
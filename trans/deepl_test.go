@@ -0,0 +1,138 @@
+package translator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+type deeplRequest struct {
+	Text       []string `json:"text"`
+	SourceLang string   `json:"source_lang"`
+	TargetLang string   `json:"target_lang"`
+}
+
+// newDeepLServer 返回把文本包上尖括号的 DeepL 兼容服务，drop 为真时少返回一条译文
+func newDeepLServer(t *testing.T, calls *atomic.Int32, drop bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Path != "/v2/translate" || r.Header.Get("Authorization") != "DeepL-Auth-Key key" {
+			t.Errorf("request = %s %v", r.URL.Path, r.Header)
+		}
+		var req deeplRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		if len(req.Text) > deeplMaxTexts {
+			http.Error(w, `{"message":"too many texts"}`, http.StatusBadRequest)
+			return
+		}
+		if drop {
+			req.Text = req.Text[1:]
+		}
+		var translations []map[string]string
+		for _, text := range req.Text {
+			translations = append(translations, map[string]string{
+				"detected_source_language": req.SourceLang,
+				"text":                     req.TargetLang + "<" + text + ">",
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]any{"translations": translations})
+	}))
+}
+
+func TestDeepLBatchChunks(t *testing.T) {
+	var calls atomic.Int32
+	srv := newDeepLServer(t, &calls, false)
+	defer srv.Close()
+
+	p := NewDeepL(DeepLConfig{AuthKey: "key", BaseURL: srv.URL})
+	reqs := make([]Request, 120)
+	for i := range reqs {
+		reqs[i] = Request{Text: strconv.Itoa(i), To: "de"}
+	}
+	results, err := p.TranslateBatch(context.Background(), reqs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 3 || len(results) != len(reqs) {
+		t.Fatalf("%d results after %d calls", len(results), calls.Load())
+	}
+	for i, res := range results {
+		if want := "DE<" + strconv.Itoa(i) + ">"; res.Target != want {
+			t.Fatalf("results[%d].Target = %q, want %q", i, res.Target, want)
+		}
+	}
+
+	// 超过请求体上限的文本分开发送
+	calls.Store(0)
+	big := strings.Repeat("x", deeplMaxBytes/2+1)
+	if _, err := p.TranslateBatch(context.Background(), []Request{{Text: big}, {Text: big}, {Text: "y"}}); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("expected 2 calls, got %d", calls.Load())
+	}
+}
+
+func TestDeepLBatch(t *testing.T) {
+	var calls atomic.Int32
+	srv := newDeepLServer(t, &calls, false)
+	defer srv.Close()
+
+	p := NewDeepL(DeepLConfig{AuthKey: "key", BaseURL: srv.URL + "/"})
+	ctx := context.Background()
+
+	// 语言对一致时合并为一次请求，语言代码转为大写
+	results, err := p.TranslateBatch(ctx, []Request{
+		{Text: "a", From: "de", To: "en-us"},
+		{Text: "b", From: "de", To: "en-us"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 1 || results[0].Target != "EN-US<a>" || results[1].Target != "EN-US<b>" || results[1].From != "DE" {
+		t.Fatalf("results = %+v after %d calls", results, calls.Load())
+	}
+
+	// 语言对不同时逐条翻译
+	calls.Store(0)
+	results, err = p.TranslateBatch(ctx, []Request{{Text: "a", To: "fr"}, {Text: "b", To: "ja"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 2 || results[0].Target != "FR<a>" || results[1].Target != "JA<b>" {
+		t.Fatalf("results = %+v after %d calls", results, calls.Load())
+	}
+}
+
+func TestDeepLCountMismatch(t *testing.T) {
+	var calls atomic.Int32
+	srv := newDeepLServer(t, &calls, true)
+	defer srv.Close()
+
+	p := NewDeepL(DeepLConfig{AuthKey: "key", BaseURL: srv.URL})
+	if _, err := p.TranslateBatch(context.Background(), []Request{{Text: "a"}, {Text: "b"}}); err == nil {
+		t.Fatal("expected a translation count mismatch error")
+	}
+}
+
+func TestDeepLQuotaExceeded(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(456)
+		w.Write([]byte(`{"message":"Quota exceeded"}`))
+	}))
+	defer srv.Close()
+
+	p := NewDeepL(DeepLConfig{AuthKey: "key", BaseURL: srv.URL})
+	_, err := p.TranslateContext(context.Background(), Request{Text: "a"})
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -0,0 +1,243 @@
+package translator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DocumentOptions 文档翻译配置
+type DocumentOptions struct {
+	MaxBytes    int     // 单次请求的字节上限，默认 MaxTextBytes
+	Concurrency int     // 并发请求数，默认 4
+	QPS         float64 // 每秒请求数上限，0 表示不限制
+}
+
+// segment 文档切分后的片段，translate 为 false 的片段（空白、换行）原样保留
+type segment struct {
+	text      string
+	translate bool
+}
+
+// TranslateDocument 翻译长文本：按行、句子切分为不超过 MaxBytes 的片段，
+// 并发翻译后按原顺序拼接，换行与首尾空白保持不变。
+func TranslateDocument(ctx context.Context, p Provider, req Request, opts DocumentOptions) (*TranslationResult, error) {
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = MaxTextBytes
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+
+	segments := splitDocument(req.Text, opts.MaxBytes)
+	translated := make([]string, len(segments))
+	var indexes []int
+	for i, seg := range segments {
+		translated[i] = seg.text
+		if seg.translate {
+			indexes = append(indexes, i)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+		from, to string
+		mu       sync.Mutex
+	)
+	lim := newLimiter(opts.QPS)
+	jobs := make(chan int)
+
+	for w := 0; w < opts.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := lim.Wait(ctx); err != nil {
+					once.Do(func() { firstErr = err })
+					cancel()
+					continue
+				}
+				res, err := p.TranslateContext(ctx, Request{Text: segments[i].text, From: req.From, To: req.To})
+				if err != nil {
+					once.Do(func() { firstErr = fmt.Errorf("translate chunk %d: %w", i, err) })
+					cancel()
+					continue
+				}
+				translated[i] = res.Target
+				mu.Lock()
+				if from == "" {
+					from, to = res.From, res.To
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, i := range indexes {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if from == "" {
+		from, to = req.From, req.To
+	}
+	return &TranslationResult{
+		Source: req.Text,
+		Target: strings.Join(translated, ""),
+		From:   from,
+		To:     to,
+	}, nil
+}
+
+// splitDocument 将文本切分为片段，所有片段顺序拼接后等于原文。
+// 可翻译片段不跨行、不含首尾空白，且不超过 maxBytes。
+func splitDocument(text string, maxBytes int) []segment {
+	var segments []segment
+	appendSep := func(s string) {
+		if s == "" {
+			return
+		}
+		if n := len(segments); n > 0 && !segments[n-1].translate {
+			segments[n-1].text += s
+			return
+		}
+		segments = append(segments, segment{text: s})
+	}
+
+	for text != "" {
+		line, rest, found := strings.Cut(text, "\n")
+		for _, chunk := range packSentences(splitSentences(line), maxBytes) {
+			core := strings.TrimSpace(chunk)
+			if core == "" {
+				appendSep(chunk)
+				continue
+			}
+			start := strings.Index(chunk, core)
+			appendSep(chunk[:start])
+			segments = append(segments, segment{text: core, translate: true})
+			appendSep(chunk[start+len(core):])
+		}
+		if found {
+			appendSep("\n")
+		}
+		text = rest
+	}
+	return segments
+}
+
+// packSentences 将句子合并为不超过 maxBytes 的块，超长句子按字符边界强制切分
+func packSentences(sentences []string, maxBytes int) []string {
+	var chunks []string
+	var cur strings.Builder
+	flush := func() {
+		if cur.Len() > 0 {
+			chunks = append(chunks, cur.String())
+			cur.Reset()
+		}
+	}
+
+	for _, s := range sentences {
+		for len(s) > maxBytes {
+			flush()
+			cut := hardCut(s, maxBytes)
+			chunks = append(chunks, s[:cut])
+			s = s[cut:]
+		}
+		if cur.Len()+len(s) > maxBytes {
+			flush()
+		}
+		cur.WriteString(s)
+	}
+	flush()
+	return chunks
+}
+
+// hardCut 返回不超过 maxBytes 的切分位置，优先在空白处切分且不拆分 UTF-8 字符
+func hardCut(s string, maxBytes int) int {
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	// 全角空格等多字节空白需按其完整长度切分
+	if i := strings.LastIndexFunc(s[:cut], unicode.IsSpace); i > 0 {
+		_, size := utf8.DecodeRuneInString(s[i:])
+		return i + size
+	}
+	if cut == 0 {
+		_, size := utf8.DecodeRuneInString(s)
+		return size
+	}
+	return cut
+}
+
+// splitSentences 按句末标点切分单行文本，拼接后等于原文
+func splitSentences(line string) []string {
+	var sentences []string
+	start := 0
+	runes := []rune(line)
+	offset := 0
+	for i, r := range runes {
+		offset += utf8.RuneLen(r)
+		if !isSentenceEnd(r) {
+			continue
+		}
+		// 英文句点后需跟空白或位于行尾，避免切分小数与缩写
+		if r == '.' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) {
+			continue
+		}
+		// 句末的后引号、括号归入当前句子
+		end := offset
+		for j := i + 1; j < len(runes) && isClosing(runes[j]); j++ {
+			end += utf8.RuneLen(runes[j])
+		}
+		if i+1 < len(runes) && isSentenceEnd(runes[i+1]) {
+			continue
+		}
+		// 句间空白归入当前句子
+		rest := line[end:]
+		end += len(rest) - len(strings.TrimLeftFunc(rest, unicode.IsSpace))
+		if end > start {
+			sentences = append(sentences, line[start:end])
+			start = end
+		}
+	}
+	if start < len(line) {
+		sentences = append(sentences, line[start:])
+	}
+	return sentences
+}
+
+func isSentenceEnd(r rune) bool {
+	switch r {
+	case '。', '！', '？', '；', '…', '!', '?', ';', '.':
+		return true
+	}
+	return false
+}
+
+func isClosing(r rune) bool {
+	switch r {
+	case '”', '’', '」', '』', '）', '"', '\'', ')', ']':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package translator
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+type upperProvider struct{}
+
+func (upperProvider) Name() string { return "upper" }
+
+func (upperProvider) TranslateContext(_ context.Context, req Request) (*TranslationResult, error) {
+	return &TranslationResult{Source: req.Text, Target: strings.ToUpper(req.Text), From: req.From, To: req.To}, nil
+}
+
+func (p upperProvider) TranslateBatch(ctx context.Context, reqs []Request) ([]*TranslationResult, error) {
+	return translateEach(ctx, p, reqs)
+}
+
+func TestSplitDocument(t *testing.T) {
+	text := "  第一段。第二句！\n\n\tHello world. Pi is 3.14, ok?  \r\n" + strings.Repeat("长", 40) + "\n"
+	segments := splitDocument(text, 30)
+
+	var joined strings.Builder
+	for _, seg := range segments {
+		joined.WriteString(seg.text)
+		if !seg.translate {
+			continue
+		}
+		if len(seg.text) > 30 {
+			t.Errorf("segment exceeds limit: %q (%d bytes)", seg.text, len(seg.text))
+		}
+		if strings.ContainsAny(seg.text, "\n") || seg.text != strings.TrimSpace(seg.text) {
+			t.Errorf("segment has newline or surrounding space: %q", seg.text)
+		}
+	}
+	if joined.String() != text {
+		t.Fatalf("segments do not reassemble:\n got %q\nwant %q", joined.String(), text)
+	}
+}
+
+func TestSplitDocumentFullWidthSpace(t *testing.T) {
+	text := "长长长　长长长长长长"
+	segments := splitDocument(text, 15)
+
+	var joined strings.Builder
+	for _, seg := range segments {
+		joined.WriteString(seg.text)
+		if !utf8.ValidString(seg.text) {
+			t.Errorf("segment is not valid UTF-8: %q", seg.text)
+		}
+	}
+	if joined.String() != text {
+		t.Fatalf("segments do not reassemble:\n got %q\nwant %q", joined.String(), text)
+	}
+	if got := hardCut(text, 15); got != 12 {
+		t.Fatalf("hardCut() = %d, want 12", got)
+	}
+}
+
+func TestSplitSentences(t *testing.T) {
+	got := splitSentences(`他说："好。"然后走了。Pi is 3.14. Done!`)
+	want := []string{`他说："好。"`, `然后走了。`, `Pi is 3.14. `, `Done!`}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("splitSentences() = %q, want %q", got, want)
+	}
+}
+
+func TestTranslateDocument(t *testing.T) {
+	text := "line one. line two.\n\n  indented line\n"
+	res, err := TranslateDocument(context.Background(), upperProvider{}, Request{Text: text, From: "en", To: "en"},
+		DocumentOptions{MaxBytes: 12, Concurrency: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := strings.ToUpper(text); res.Target != want {
+		t.Fatalf("Target = %q, want %q", res.Target, want)
+	}
+}
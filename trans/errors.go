@@ -0,0 +1,114 @@
+package translator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// 错误分类，可通过 errors.Is 判断，具体信息通过 errors.As 获取 *APIError
+var (
+	ErrAuth                = errors.New("authentication failed")
+	ErrQuotaExceeded       = errors.New("quota exceeded")
+	ErrUnsupportedLanguage = errors.New("unsupported language")
+	ErrTextTooLong         = errors.New("text too long")
+	ErrRateLimited         = errors.New("rate limited")
+	ErrServer              = errors.New("server error")
+)
+
+// APIError 翻译服务返回的错误
+type APIError struct {
+	Provider   string // 服务提供方名称
+	HTTPStatus int    // HTTP 状态码
+	Code       string // 服务端业务错误码，HTTP 层错误时为空
+	Message    string // 服务端错误信息
+	SID        string // 讯飞会话 ID，便于向服务方排查
+	Kind       error  // 错误分类，无法归类时为 nil
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: ", e.Provider)
+	if e.Code != "" {
+		fmt.Fprintf(&b, "code %s", e.Code)
+	} else {
+		fmt.Fprintf(&b, "http %d", e.HTTPStatus)
+	}
+	if e.Message != "" {
+		fmt.Fprintf(&b, ": %s", e.Message)
+	}
+	if e.SID != "" {
+		fmt.Fprintf(&b, " (sid %s)", e.SID)
+	}
+	return b.String()
+}
+
+// Unwrap 返回错误分类，使 errors.Is(err, ErrAuth) 等判断生效
+func (e *APIError) Unwrap() error {
+	return e.Kind
+}
+
+// kindForStatus 按 HTTP 状态码归类错误
+func kindForStatus(status int) error {
+	switch {
+	case status == http.StatusUnauthorized, status == http.StatusForbidden:
+		return ErrAuth
+	case status == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case status == http.StatusRequestEntityTooLarge:
+		return ErrTextTooLong
+	case status == 456: // DeepL: Quota exceeded
+		return ErrQuotaExceeded
+	case status >= 500:
+		return ErrServer
+	}
+	return nil
+}
+
+// statusError 根据非 200 响应构造错误，尽量从响应体中提取错误信息
+func statusError(provider string, status int, body []byte) *APIError {
+	var msg struct {
+		Message string `json:"message"`
+		Error   string `json:"error"`
+	}
+	message := strings.TrimSpace(string(body))
+	if json.Unmarshal(body, &msg) == nil {
+		if msg.Message != "" {
+			message = msg.Message
+		} else if msg.Error != "" {
+			message = msg.Error
+		}
+	}
+	return &APIError{
+		Provider:   provider,
+		HTTPStatus: status,
+		Message:    message,
+		Kind:       kindForStatus(status),
+	}
+}
+
+// xfyunErrorKinds 讯飞业务错误码归类
+var xfyunErrorKinds = map[int]error{
+	10105: ErrAuth,                // 没有权限
+	10110: ErrAuth,                // 无授权许可
+	10313: ErrAuth,                // appid 与 apikey 不匹配
+	11200: ErrAuth,                // 功能未授权
+	11201: ErrQuotaExceeded,       // 日流控超限
+	10107: ErrUnsupportedLanguage, // 参数值非法，OTS 中通常为语种不支持
+	10109: ErrTextTooLong,         // 请求文本长度非法
+	10700: ErrServer,              // 引擎错误
+}
+
+// baiduErrorKinds 百度业务错误码归类
+var baiduErrorKinds = map[string]error{
+	"52001": ErrServer,              // 请求超时
+	"52002": ErrServer,              // 系统错误
+	"52003": ErrAuth,                // 未授权用户
+	"54001": ErrAuth,                // 签名错误
+	"54003": ErrRateLimited,         // 访问频率受限
+	"54004": ErrQuotaExceeded,       // 账户余额不足
+	"54005": ErrRateLimited,         // 长 query 请求频繁
+	"58001": ErrUnsupportedLanguage, // 译文语言方向不支持
+}
@@ -0,0 +1,135 @@
+package translator
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Glossary 术语表，强制指定术语的译法。
+// From/To 为空时对所有语言对生效。
+type Glossary struct {
+	From  string
+	To    string
+	Terms map[string]string // 原文术语 → 译文
+}
+
+// placeholderRe 匹配术语占位符，容忍翻译服务在其中插入空白
+var placeholderRe = regexp.MustCompile(`__\s*GLS\s*(\d+)\s*__`)
+
+// glossary 为 Provider 增加术语表
+type glossary struct {
+	Provider
+	glossaries []Glossary
+}
+
+// WithGlossary 为 Provider 增加术语表：发送前将术语替换为占位符，翻译后还原为指定译文
+func WithGlossary(p Provider, glossaries ...Glossary) Provider {
+	return &glossary{Provider: p, glossaries: glossaries}
+}
+
+// TranslateContext 保护术语后翻译
+func (g *glossary) TranslateContext(ctx context.Context, req Request) (*TranslationResult, error) {
+	protected, targets := g.protect(req)
+	res, err := g.Provider.TranslateContext(ctx, protected)
+	if err != nil {
+		return nil, err
+	}
+	return restoreTerms(res, req.Text, targets), nil
+}
+
+// TranslateBatch 保护术语后批量翻译
+func (g *glossary) TranslateBatch(ctx context.Context, reqs []Request) ([]*TranslationResult, error) {
+	protected := make([]Request, len(reqs))
+	targets := make([][]string, len(reqs))
+	for i, req := range reqs {
+		protected[i], targets[i] = g.protect(req)
+	}
+	results, err := g.Provider.TranslateBatch(ctx, protected)
+	for i, res := range results {
+		if res != nil {
+			results[i] = restoreTerms(res, reqs[i].Text, targets[i])
+		}
+	}
+	return results, err
+}
+
+// protect 将匹配的术语替换为 __GLS<n>__，返回占位符对应的译文
+func (g *glossary) protect(req Request) (Request, []string) {
+	terms := make(map[string]string)
+	for _, gl := range g.glossaries {
+		if (gl.From != "" && gl.From != req.From) || (gl.To != "" && gl.To != req.To) {
+			continue
+		}
+		for src, dst := range gl.Terms {
+			terms[src] = dst
+		}
+	}
+	if len(terms) == 0 {
+		return req, nil
+	}
+
+	// 长术语优先，避免被其子串抢先匹配
+	keys := make([]string, 0, len(terms))
+	for src := range terms {
+		if src != "" {
+			keys = append(keys, src)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	quoted := make([]string, len(keys))
+	for i, k := range keys {
+		quoted[i] = termPattern(k)
+	}
+	re := regexp.MustCompile(strings.Join(quoted, "|"))
+
+	var targets []string
+	req.Text = re.ReplaceAllStringFunc(req.Text, func(term string) string {
+		targets = append(targets, terms[term])
+		return fmt.Sprintf("__GLS%d__", len(targets)-1)
+	})
+	return req, targets
+}
+
+// termPattern 返回术语的正则：以单词字符开头或结尾的一侧加 \b，
+// 避免 "go" 匹配 "good"；中文等术语两侧没有单词边界，仍按子串匹配
+func termPattern(term string) string {
+	p := regexp.QuoteMeta(term)
+	if isWordByte(term[0]) {
+		p = `\b` + p
+	}
+	if isWordByte(term[len(term)-1]) {
+		p += `\b`
+	}
+	return p
+}
+
+// isWordByte 与 regexp 中 \w 的定义一致
+func isWordByte(c byte) bool {
+	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// restoreTerms 将译文中的占位符还原为术语译文
+func restoreTerms(res *TranslationResult, source string, targets []string) *TranslationResult {
+	out := *res
+	out.Source = source
+	if len(targets) == 0 {
+		return &out
+	}
+	out.Target = placeholderRe.ReplaceAllStringFunc(res.Target, func(m string) string {
+		n, err := strconv.Atoi(placeholderRe.FindStringSubmatch(m)[1])
+		if err != nil || n >= len(targets) {
+			return m
+		}
+		return targets[n]
+	})
+	return &out
+}
@@ -0,0 +1,297 @@
+package translator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// AutoLanguage 表示自动检测源语言
+const AutoLanguage = "auto"
+
+// LanguagePair 语言对，使用 BCP-47 标签
+type LanguagePair struct {
+	From string
+	To   string
+}
+
+// languageCodes BCP-47 标签 → 各服务的语言代码
+var languageCodes = map[string]map[string]string{
+	"xfyun": {
+		"zh": "cn", "zh-TW": "cht", "en": "en", "ja": "ja", "ko": "ko", "fr": "fr", "de": "de",
+		"es": "es", "ru": "ru", "pt": "pt", "it": "it", "ar": "ar", "th": "th", "vi": "vi",
+	},
+	"baidu": {
+		"zh": "zh", "zh-TW": "cht", "en": "en", "ja": "jp", "ko": "kor", "fr": "fra", "de": "de",
+		"es": "spa", "ru": "ru", "pt": "pt", "it": "it", "ar": "ara", "th": "th", "vi": "vie",
+	},
+	"deepl": {
+		"zh": "ZH", "zh-TW": "ZH-HANT", "en": "EN", "ja": "JA", "ko": "KO", "fr": "FR", "de": "DE",
+		"es": "ES", "ru": "RU", "pt": "PT", "it": "IT", "ar": "AR",
+	},
+	"libretranslate": {
+		"zh": "zh", "zh-TW": "zt", "en": "en", "ja": "ja", "ko": "ko", "fr": "fr", "de": "de",
+		"es": "es", "ru": "ru", "pt": "pt", "it": "it", "ar": "ar", "th": "th",
+	},
+}
+
+// nativeAuto 支持服务端自动识别源语言的服务及其代码
+var nativeAuto = map[string]string{
+	"baidu":          "auto",
+	"deepl":          "",
+	"libretranslate": "auto",
+}
+
+// CanonicalLanguage 将语言标签规范化为本包使用的 BCP-47 形式，
+// 如 "zh_CN"、"zh-Hans" → "zh"，"zh-HK"、"zh-Hant" → "zh-TW"，"en-US" → "en"。
+func CanonicalLanguage(tag string) string {
+	tag = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+	if tag == "" || tag == AutoLanguage {
+		return tag
+	}
+	parts := strings.Split(tag, "-")
+	switch parts[0] {
+	case "zh", "cn", "chs":
+		for _, sub := range parts[1:] {
+			switch sub {
+			case "tw", "hk", "mo", "hant":
+				return "zh-TW"
+			}
+		}
+		return "zh"
+	case "cht":
+		return "zh-TW"
+	}
+	return parts[0]
+}
+
+// ToProvider 将 BCP-47 语言标签转换为服务方语言代码
+func ToProvider(provider, tag string) (string, error) {
+	codes, ok := languageCodes[provider]
+	if !ok {
+		return "", fmt.Errorf("unknown provider %q", provider)
+	}
+	code, ok := codes[CanonicalLanguage(tag)]
+	if !ok {
+		return "", fmt.Errorf("%w: %s does not support %q", ErrUnsupportedLanguage, provider, tag)
+	}
+	return code, nil
+}
+
+// FromProvider 将服务方语言代码转换为 BCP-47 标签
+func FromProvider(provider, code string) (string, error) {
+	for tag, c := range languageCodes[provider] {
+		if strings.EqualFold(c, code) {
+			return tag, nil
+		}
+	}
+	// DeepL 会返回 EN-US、PT-BR 等带地区的代码
+	if tag := CanonicalLanguage(code); tag != "" {
+		if _, ok := languageCodes[provider][tag]; ok {
+			return tag, nil
+		}
+	}
+	return "", fmt.Errorf("%w: unknown %s language code %q", ErrUnsupportedLanguage, provider, code)
+}
+
+// SupportedLanguages 返回服务支持的 BCP-47 语言标签
+func SupportedLanguages(provider string) []string {
+	tags := make([]string, 0, len(languageCodes[provider]))
+	for tag := range languageCodes[provider] {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// SupportedPairs 返回服务支持的语言对
+func SupportedPairs(provider string) []LanguagePair {
+	var pairs []LanguagePair
+	for _, from := range SupportedLanguages(provider) {
+		for _, to := range SupportedLanguages(provider) {
+			if IsSupported(provider, from, to) {
+				pairs = append(pairs, LanguagePair{From: from, To: to})
+			}
+		}
+	}
+	return pairs
+}
+
+// IsSupported 判断服务是否支持该语言对。讯飞仅支持中文或英文与其它语种互译。
+func IsSupported(provider, from, to string) bool {
+	from, to = CanonicalLanguage(from), CanonicalLanguage(to)
+	codes := languageCodes[provider]
+	if _, ok := codes[from]; !ok {
+		return false
+	}
+	if _, ok := codes[to]; !ok || from == to {
+		return false
+	}
+	if provider == "xfyun" {
+		hub := func(tag string) bool { return tag == "zh" || tag == "en" }
+		return hub(from) || hub(to)
+	}
+	return true
+}
+
+// Detector 语言检测器，返回 BCP-47 标签
+type Detector interface {
+	Detect(ctx context.Context, text string) (string, error)
+}
+
+// DetectorFunc 将函数适配为 Detector
+type DetectorFunc func(ctx context.Context, text string) (string, error)
+
+func (f DetectorFunc) Detect(ctx context.Context, text string) (string, error) {
+	return f(ctx, text)
+}
+
+// ScriptDetector 按文字系统粗略识别语言：汉字、假名、谚文、西里尔、阿拉伯、泰文，
+// 拉丁字母一律识别为英文。
+var ScriptDetector Detector = DetectorFunc(func(_ context.Context, text string) (string, error) {
+	counts := make(map[string]int)
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			counts["ja"] += 2 // 假名是日文的强特征
+		case unicode.Is(unicode.Han, r):
+			counts["zh"]++
+		case unicode.Is(unicode.Hangul, r):
+			counts["ko"]++
+		case unicode.Is(unicode.Cyrillic, r):
+			counts["ru"]++
+		case unicode.Is(unicode.Arabic, r):
+			counts["ar"]++
+		case unicode.Is(unicode.Thai, r):
+			counts["th"]++
+		case unicode.Is(unicode.Latin, r):
+			counts["en"]++
+		}
+	}
+	if counts["ja"] > 0 {
+		return "ja", nil
+	}
+	best, most := "", 0
+	for _, tag := range []string{"zh", "ko", "ru", "ar", "th", "en"} {
+		if counts[tag] > most {
+			best, most = tag, counts[tag]
+		}
+	}
+	if best == "" {
+		return "", fmt.Errorf("cannot detect language")
+	}
+	return best, nil
+})
+
+// languages 将 BCP-47 标签转换为服务方代码
+type languages struct {
+	Provider
+	detector Detector
+}
+
+// WithLanguages 使 Provider 接受 BCP-47 语言标签，并支持 "auto" 源语言。
+// 源语言为 "auto" 或空时：设置了 detector 则先检测，否则使用服务端自动识别；
+// 服务不支持自动识别时回退到 ScriptDetector。检测结果与目标语言相同时原文返回。
+func WithLanguages(p Provider, detector Detector) Provider {
+	return &languages{Provider: p, detector: detector}
+}
+
+// TranslateContext 转换语言代码后翻译
+func (l *languages) TranslateContext(ctx context.Context, req Request) (*TranslationResult, error) {
+	mapped, skip, err := l.mapRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if skip != nil {
+		return skip, nil
+	}
+	res, err := l.Provider.TranslateContext(ctx, mapped)
+	if err != nil {
+		return nil, err
+	}
+	return l.mapResult(res, req), nil
+}
+
+// TranslateBatch 转换语言代码后批量翻译，无需翻译的条目不会发送
+func (l *languages) TranslateBatch(ctx context.Context, reqs []Request) ([]*TranslationResult, error) {
+	results := make([]*TranslationResult, len(reqs))
+	var (
+		mapped  []Request
+		indexes []int
+	)
+	for i, req := range reqs {
+		m, skip, err := l.mapRequest(ctx, req)
+		if err != nil {
+			return results, fmt.Errorf("translate item %d: %w", i, err)
+		}
+		if skip != nil {
+			results[i] = skip
+			continue
+		}
+		mapped = append(mapped, m)
+		indexes = append(indexes, i)
+	}
+	if len(mapped) == 0 {
+		return results, nil
+	}
+
+	translated, err := l.Provider.TranslateBatch(ctx, mapped)
+	for j, res := range translated {
+		if res != nil {
+			results[indexes[j]] = l.mapResult(res, reqs[indexes[j]])
+		}
+	}
+	return results, err
+}
+
+// mapRequest 返回使用服务方代码的请求；源语言与目标语言相同时返回无需翻译的结果
+func (l *languages) mapRequest(ctx context.Context, req Request) (Request, *TranslationResult, error) {
+	name := l.Name()
+	from, to := CanonicalLanguage(req.From), CanonicalLanguage(req.To)
+	if to == "" {
+		return req, nil, fmt.Errorf("target language is required")
+	}
+
+	if from == "" || from == AutoLanguage {
+		detector := l.detector
+		if detector == nil {
+			if code, ok := nativeAuto[name]; ok {
+				toCode, err := ToProvider(name, to)
+				if err != nil {
+					return req, nil, err
+				}
+				return Request{Text: req.Text, From: code, To: toCode}, nil, nil
+			}
+			detector = ScriptDetector
+		}
+		detected, err := detector.Detect(ctx, req.Text)
+		if err != nil {
+			return req, nil, fmt.Errorf("detect language failed: %w", err)
+		}
+		from = CanonicalLanguage(detected)
+	}
+
+	if from == to {
+		return req, &TranslationResult{Source: req.Text, Target: req.Text, From: from, To: to}, nil
+	}
+	if !IsSupported(name, from, to) {
+		return req, nil, fmt.Errorf("%w: %s does not support %s → %s", ErrUnsupportedLanguage, name, from, to)
+	}
+	fromCode, _ := ToProvider(name, from)
+	toCode, _ := ToProvider(name, to)
+	return Request{Text: req.Text, From: fromCode, To: toCode}, nil, nil
+}
+
+// mapResult 将结果中的语言代码还原为 BCP-47 标签
+func (l *languages) mapResult(res *TranslationResult, req Request) *TranslationResult {
+	out := *res
+	if tag, err := FromProvider(l.Name(), res.From); err == nil {
+		out.From = tag
+	} else if from := CanonicalLanguage(req.From); from != AutoLanguage {
+		out.From = from
+	}
+	out.To = CanonicalLanguage(req.To)
+	return &out
+}
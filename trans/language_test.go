@@ -0,0 +1,70 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type echoProvider struct{ name string }
+
+func (p echoProvider) Name() string { return p.name }
+
+func (p echoProvider) TranslateContext(_ context.Context, req Request) (*TranslationResult, error) {
+	return &TranslationResult{Source: req.Text, Target: req.From + ">" + req.To, From: req.From, To: req.To}, nil
+}
+
+func (p echoProvider) TranslateBatch(ctx context.Context, reqs []Request) ([]*TranslationResult, error) {
+	return translateEach(ctx, p, reqs)
+}
+
+func TestCanonicalLanguage(t *testing.T) {
+	cases := map[string]string{
+		"zh_CN": "zh", "zh-Hans": "zh", "zh-HK": "zh-TW", "zh-Hant-TW": "zh-TW",
+		"en-US": "en", "PT-br": "pt", "auto": "auto",
+	}
+	for in, want := range cases {
+		if got := CanonicalLanguage(in); got != want {
+			t.Errorf("CanonicalLanguage(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestProviderCodes(t *testing.T) {
+	if code, _ := ToProvider("baidu", "ja-JP"); code != "jp" {
+		t.Errorf("ToProvider(baidu, ja-JP) = %q", code)
+	}
+	if tag, _ := FromProvider("deepl", "EN-US"); tag != "en" {
+		t.Errorf("FromProvider(deepl, EN-US) = %q", tag)
+	}
+	if _, err := ToProvider("deepl", "th"); !errors.Is(err, ErrUnsupportedLanguage) {
+		t.Errorf("expected ErrUnsupportedLanguage, got %v", err)
+	}
+	if IsSupported("xfyun", "ja", "ko") || !IsSupported("xfyun", "ja", "zh") {
+		t.Error("xfyun pairs must include zh or en")
+	}
+}
+
+func TestWithLanguages(t *testing.T) {
+	ctx := context.Background()
+
+	p := WithLanguages(echoProvider{name: "xfyun"}, nil)
+	res, err := p.TranslateContext(ctx, Request{Text: "こんにちは世界", From: AutoLanguage, To: "zh-CN"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Target != "ja>cn" || res.From != "ja" || res.To != "zh" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	res, err = p.TranslateContext(ctx, Request{Text: "你好", To: "zh"})
+	if err != nil || res.Target != "你好" {
+		t.Fatalf("same-language text should be returned as is, got %+v, %v", res, err)
+	}
+
+	// baidu 支持服务端自动识别
+	res, err = WithLanguages(echoProvider{name: "baidu"}, nil).TranslateContext(ctx, Request{Text: "hi", To: "ko"})
+	if err != nil || res.Target != "auto>kor" {
+		t.Fatalf("unexpected result: %+v, %v", res, err)
+	}
+}
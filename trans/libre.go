@@ -0,0 +1,147 @@
+package translator
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// LibreConfig LibreTranslate 配置
+type LibreConfig struct {
+	BaseURL    string // 如 https://libretranslate.com
+	APIKey     string // 自建实例可为空
+	FromLang   string // 默认 auto
+	ToLang     string // 默认 en
+	HTTPClient *http.Client
+	Policy     *Policy // 限速、重试与熔断策略，为空时不启用
+}
+
+// LibreTranslate 实例通常限制单次请求的字符数，按保守值分组
+const (
+	libreMaxTexts = 50
+	libreMaxBytes = MaxTextBytes
+)
+
+type libre struct {
+	client   *http.Client
+	guard    *guard
+	baseURL  string
+	apiKey   string
+	fromLang string
+	toLang   string
+}
+
+// NewLibreTranslate 创建 LibreTranslate 翻译服务
+func NewLibreTranslate(config LibreConfig) Provider {
+	l := &libre{
+		client:   config.HTTPClient,
+		baseURL:  strings.TrimSuffix(config.BaseURL, "/"),
+		apiKey:   config.APIKey,
+		fromLang: config.FromLang,
+		toLang:   config.ToLang,
+	}
+	if config.Policy != nil {
+		l.guard = newGuard(*config.Policy)
+	}
+	if l.client == nil {
+		l.client = &http.Client{Timeout: defaultTimeout}
+	}
+	if l.baseURL == "" {
+		l.baseURL = "https://libretranslate.com"
+	}
+	if l.fromLang == "" {
+		l.fromLang = "auto"
+	}
+	if l.toLang == "" {
+		l.toLang = "en"
+	}
+	return l
+}
+
+// Name 返回服务提供方名称
+func (l *libre) Name() string {
+	return "libretranslate"
+}
+
+// TranslateContext 翻译单条文本
+func (l *libre) TranslateContext(ctx context.Context, req Request) (*TranslationResult, error) {
+	results, err := l.TranslateBatch(ctx, []Request{req})
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+// TranslateBatch 语言对一致时分组合并请求，否则逐条翻译
+func (l *libre) TranslateBatch(ctx context.Context, reqs []Request) ([]*TranslationResult, error) {
+	if len(reqs) == 0 {
+		return nil, nil
+	}
+	if !sameLanguages(reqs) {
+		return translateEach(ctx, l, reqs)
+	}
+	return translateChunks(reqs, libreMaxTexts, libreMaxBytes, func(chunk []Request) ([]*TranslationResult, error) {
+		return l.translate(ctx, chunk)
+	})
+}
+
+// translate 以一次请求翻译同一语言对的文本
+func (l *libre) translate(ctx context.Context, reqs []Request) ([]*TranslationResult, error) {
+	from, to := reqs[0].From, reqs[0].To
+	if from == "" {
+		from = l.fromLang
+	}
+	if to == "" {
+		to = l.toLang
+	}
+
+	texts := make([]string, len(reqs))
+	for i, req := range reqs {
+		texts[i] = req.Text
+	}
+	body := map[string]any{
+		"q":      texts,
+		"source": from,
+		"target": to,
+		"format": "text",
+	}
+	if l.apiKey != "" {
+		body["api_key"] = l.apiKey
+	}
+
+	var resp struct {
+		TranslatedText   []string `json:"translatedText"`
+		DetectedLanguage []struct {
+			Language string `json:"language"`
+		} `json:"detectedLanguage"` // 仅 source 为 auto 时返回
+		Error string `json:"error"`
+	}
+	err := l.guard.Do(ctx, func() error {
+		return postJSON(ctx, l.client, l.Name(), l.baseURL+"/translate", nil, body, &resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != "" {
+		return nil, &APIError{Provider: l.Name(), HTTPStatus: http.StatusOK, Message: resp.Error}
+	}
+	if len(resp.TranslatedText) != len(reqs) {
+		return nil, fmt.Errorf("expected %d translations, got %d", len(reqs), len(resp.TranslatedText))
+	}
+
+	results := make([]*TranslationResult, len(reqs))
+	for i, text := range resp.TranslatedText {
+		detected := from
+		if i < len(resp.DetectedLanguage) && resp.DetectedLanguage[i].Language != "" {
+			detected = resp.DetectedLanguage[i].Language
+		}
+		results[i] = &TranslationResult{
+			Source: reqs[i].Text,
+			Target: text,
+			From:   detected,
+			To:     to,
+		}
+	}
+	return results, nil
+}
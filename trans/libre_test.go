@@ -0,0 +1,119 @@
+package translator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestLibreTranslateBatch(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		var req struct {
+			Q      []string `json:"q"`
+			Source string   `json:"source"`
+			Target string   `json:"target"`
+			Format string   `json:"format"`
+			APIKey string   `json:"api_key"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		if r.URL.Path != "/translate" || req.APIKey != "key" || req.Format != "text" {
+			t.Errorf("request = %s %+v", r.URL.Path, req)
+		}
+		out := make([]string, len(req.Q))
+		for i, q := range req.Q {
+			out[i] = req.Source + ">" + req.Target + ":" + q
+		}
+		json.NewEncoder(w).Encode(map[string]any{"translatedText": out})
+	}))
+	defer srv.Close()
+
+	p := NewLibreTranslate(LibreConfig{BaseURL: srv.URL, APIKey: "key"})
+	ctx := context.Background()
+
+	results, err := p.TranslateBatch(ctx, []Request{{Text: "a"}, {Text: "b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 1 || results[0].Target != "auto>en:a" || results[1].Target != "auto>en:b" {
+		t.Fatalf("results = %+v after %d calls", results, calls.Load())
+	}
+
+	calls.Store(0)
+	results, err = p.TranslateBatch(ctx, []Request{{Text: "a", To: "de"}, {Text: "b", To: "fr"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 2 || results[0].Target != "auto>de:a" || results[1].Target != "auto>fr:b" {
+		t.Fatalf("results = %+v after %d calls", results, calls.Load())
+	}
+}
+
+func TestLibreTranslateDetectedLanguage(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		var req struct {
+			Q []string `json:"q"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		if len(req.Q) > libreMaxTexts {
+			t.Errorf("%d texts in one request", len(req.Q))
+		}
+		detected := make([]map[string]any, len(req.Q))
+		for i := range detected {
+			detected[i] = map[string]any{"confidence": 90, "language": "fr"}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"translatedText": req.Q, "detectedLanguage": detected})
+	}))
+	defer srv.Close()
+
+	p := NewLibreTranslate(LibreConfig{BaseURL: srv.URL})
+	reqs := make([]Request, libreMaxTexts+1)
+	for i := range reqs {
+		reqs[i] = Request{Text: "bonjour"}
+	}
+	results, err := p.TranslateBatch(context.Background(), reqs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 2 || len(results) != len(reqs) {
+		t.Fatalf("%d results after %d calls", len(results), calls.Load())
+	}
+	if results[0].From != "fr" || results[len(results)-1].From != "fr" {
+		t.Fatalf("From = %q, want detected language", results[0].From)
+	}
+}
+
+func TestLibreTranslateErrors(t *testing.T) {
+	status := http.StatusTooManyRequests
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			w.Write([]byte(`{"translatedText":[]}`))
+			return
+		}
+		w.Write([]byte(`{"error":"Slowdown"}`))
+	}))
+	defer srv.Close()
+
+	p := NewLibreTranslate(LibreConfig{BaseURL: srv.URL})
+	ctx := context.Background()
+	if _, err := p.TranslateContext(ctx, Request{Text: "a"}); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("err = %v", err)
+	}
+
+	status = http.StatusOK
+	if _, err := p.TranslateContext(ctx, Request{Text: "a"}); err == nil {
+		t.Fatal("expected a translation count mismatch error")
+	}
+}
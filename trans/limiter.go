@@ -0,0 +1,51 @@
+package translator
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// limiter 按固定间隔放行请求的简单限速器，nil 表示不限速
+type limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// newLimiter 创建每秒最多放行 qps 个请求的限速器，qps <= 0 时返回 nil
+func newLimiter(qps float64) *limiter {
+	if qps <= 0 {
+		return nil
+	}
+	return &limiter{interval: time.Duration(float64(time.Second) / qps)}
+}
+
+// Wait 阻塞直到允许下一个请求或 ctx 结束
+func (l *limiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(at)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
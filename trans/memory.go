@@ -0,0 +1,176 @@
+package translator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/package-register/go-toolkit/cache"
+)
+
+// Store 翻译记忆存储
+type Store interface {
+	Get(key string) (string, bool)
+	Set(key, value string)
+}
+
+// CacheStore 基于 cache.Cache 的内存翻译记忆
+type CacheStore struct {
+	c   *cache.Cache
+	ttl time.Duration
+}
+
+// NewCacheStore 创建内存翻译记忆，ttl 为 0 时条目保留 24 小时
+func NewCacheStore(c *cache.Cache, ttl time.Duration) *CacheStore {
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	return &CacheStore{c: c, ttl: ttl}
+}
+
+func (s *CacheStore) Get(key string) (string, bool) {
+	v, ok := s.c.Get(key)
+	if !ok {
+		return "", false
+	}
+	text, ok := v.(string)
+	return text, ok
+}
+
+func (s *CacheStore) Set(key, value string) {
+	s.c.Add(key, value, s.ttl)
+}
+
+// FileStore 以 JSON 文件持久化的翻译记忆，修改后需调用 Save 写回磁盘
+type FileStore struct {
+	path  string
+	mu    sync.RWMutex
+	items map[string]string
+}
+
+// NewFileStore 打开翻译记忆文件，文件不存在时创建空记忆
+func NewFileStore(path string) (*FileStore, error) {
+	s := &FileStore{path: path, items: make(map[string]string)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read memory file failed: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.items); err != nil {
+		return nil, fmt.Errorf("parse memory file failed: %w", err)
+	}
+	return s, nil
+}
+
+func (s *FileStore) Get(key string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.items[key]
+	return v, ok
+}
+
+func (s *FileStore) Set(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items[key] = value
+}
+
+// Save 将翻译记忆写回文件（先写临时文件再重命名）
+func (s *FileStore) Save() error {
+	s.mu.RLock()
+	data, err := json.MarshalIndent(s.items, "", "  ")
+	s.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("marshal memory failed: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".memory-*.json")
+	if err != nil {
+		return fmt.Errorf("create temp file failed: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write memory file failed: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write memory file failed: %w", err)
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// memory 为 Provider 增加翻译记忆
+type memory struct {
+	Provider
+	store Store
+}
+
+// WithMemory 为 Provider 增加翻译记忆，命中时不再调用翻译服务。
+// 记忆以 (服务名, 源语言, 目标语言, 规范化原文) 为键。
+func WithMemory(p Provider, store Store) Provider {
+	return &memory{Provider: p, store: store}
+}
+
+// TranslateContext 优先返回翻译记忆中的结果
+func (m *memory) TranslateContext(ctx context.Context, req Request) (*TranslationResult, error) {
+	key := memoryKey(m.Name(), req)
+	if target, ok := m.store.Get(key); ok {
+		return &TranslationResult{Source: req.Text, Target: target, From: req.From, To: req.To}, nil
+	}
+	res, err := m.Provider.TranslateContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	m.store.Set(key, res.Target)
+	return res, nil
+}
+
+// TranslateBatch 仅将未命中记忆的条目交给下层服务
+func (m *memory) TranslateBatch(ctx context.Context, reqs []Request) ([]*TranslationResult, error) {
+	results := make([]*TranslationResult, len(reqs))
+	var (
+		misses  []Request
+		indexes []int
+	)
+	for i, req := range reqs {
+		if target, ok := m.store.Get(memoryKey(m.Name(), req)); ok {
+			results[i] = &TranslationResult{Source: req.Text, Target: target, From: req.From, To: req.To}
+			continue
+		}
+		misses = append(misses, req)
+		indexes = append(indexes, i)
+	}
+	if len(misses) == 0 {
+		return results, nil
+	}
+
+	translated, err := m.Provider.TranslateBatch(ctx, misses)
+	for j, res := range translated {
+		if res == nil {
+			continue
+		}
+		results[indexes[j]] = res
+		m.store.Set(memoryKey(m.Name(), misses[j]), res.Target)
+	}
+	return results, err
+}
+
+// memoryKey 生成翻译记忆键，原文经规范化后取 SHA-256
+func memoryKey(provider string, req Request) string {
+	sum := sha256.Sum256([]byte(normalizeText(req.Text)))
+	return provider + "|" + req.From + "|" + req.To + "|" + hex.EncodeToString(sum[:])
+}
+
+// normalizeText 去除首尾空白并合并连续空白
+func normalizeText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
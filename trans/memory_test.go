@@ -0,0 +1,107 @@
+package translator
+
+import (
+	"context"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/package-register/go-toolkit/cache"
+)
+
+type countingProvider struct {
+	upperProvider
+	calls atomic.Int32
+}
+
+func (p *countingProvider) TranslateContext(ctx context.Context, req Request) (*TranslationResult, error) {
+	p.calls.Add(1)
+	return p.upperProvider.TranslateContext(ctx, req)
+}
+
+func (p *countingProvider) TranslateBatch(ctx context.Context, reqs []Request) ([]*TranslationResult, error) {
+	return translateEach(ctx, p, reqs)
+}
+
+func TestWithMemory(t *testing.T) {
+	c := cache.NewCache(time.Minute)
+	defer c.Stop()
+	inner := &countingProvider{}
+	p := WithMemory(inner, NewCacheStore(c, 0))
+	ctx := context.Background()
+
+	if _, err := p.TranslateContext(ctx, Request{Text: "hello  world", From: "en", To: "de"}); err != nil {
+		t.Fatal(err)
+	}
+	res, err := p.TranslateContext(ctx, Request{Text: " hello world ", From: "en", To: "de"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Target != "HELLO  WORLD" || inner.calls.Load() != 1 {
+		t.Fatalf("expected memory hit, got %q after %d calls", res.Target, inner.calls.Load())
+	}
+
+	results, err := p.TranslateBatch(ctx, []Request{
+		{Text: "hello world", From: "en", To: "de"},
+		{Text: "hello world", From: "en", To: "fr"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || inner.calls.Load() != 2 {
+		t.Fatalf("expected only the fr entry to miss, got %d calls", inner.calls.Load())
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memory.json")
+	s, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Set("k", "v")
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := reopened.Get("k"); !ok || v != "v" {
+		t.Fatalf("Get() = %q, %v", v, ok)
+	}
+}
+
+func TestWithGlossary(t *testing.T) {
+	p := WithGlossary(upperProvider{},
+		Glossary{Terms: map[string]string{"go": "Go", "go-toolkit": "Go Toolkit"}},
+		Glossary{From: "en", To: "fr", Terms: map[string]string{"toolkit": "boîte à outils"}},
+	)
+
+	res, err := p.TranslateContext(context.Background(), Request{Text: "use go-toolkit with go", From: "en", To: "de"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "USE Go Toolkit WITH Go"; res.Target != want {
+		t.Fatalf("Target = %q, want %q", res.Target, want)
+	}
+	if res.Source != "use go-toolkit with go" {
+		t.Fatalf("Source = %q", res.Source)
+	}
+
+	// 术语只按整词匹配，中文术语仍按子串匹配
+	p = WithGlossary(upperProvider{}, Glossary{Terms: map[string]string{"go": "Go", "工具": "tool"}})
+	res, err = p.TranslateContext(context.Background(), Request{Text: "good go gopher 工具箱", From: "en", To: "de"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "GOOD Go GOPHER tool箱"; res.Target != want {
+		t.Fatalf("Target = %q, want %q", res.Target, want)
+	}
+
+	if got := restoreTerms(&TranslationResult{Target: "x __ GLS0 __ y"}, "", []string{"Go"}); got.Target != "x Go y" {
+		t.Fatalf("restoreTerms() = %q", got.Target)
+	}
+}
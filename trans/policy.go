@@ -0,0 +1,156 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen 熔断器打开期间请求被直接拒绝
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// Policy HTTP 调用的限速、重试与熔断策略，零值表示均不启用
+type Policy struct {
+	QPS              float64       // 每秒请求数上限，0 表示不限制
+	MaxRetries       int           // 瞬时错误（网络错误、429、5xx）的最大重试次数
+	BaseDelay        time.Duration // 首次重试等待时间，之后指数增长，默认 200ms
+	MaxDelay         time.Duration // 重试等待上限，默认 5s
+	BreakerThreshold int           // 连续失败多少次后熔断，0 表示不启用
+	BreakerCooldown  time.Duration // 熔断持续时间，默认 30s
+}
+
+// DefaultPolicy 返回推荐的默认策略
+func DefaultPolicy() Policy {
+	return Policy{
+		QPS:              0,
+		MaxRetries:       3,
+		BaseDelay:        200 * time.Millisecond,
+		MaxDelay:         5 * time.Second,
+		BreakerThreshold: 5,
+		BreakerCooldown:  30 * time.Second,
+	}
+}
+
+// guard 按 Policy 执行请求
+type guard struct {
+	policy  Policy
+	limiter *limiter
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func newGuard(policy Policy) *guard {
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = 200 * time.Millisecond
+	}
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = 5 * time.Second
+	}
+	if policy.BreakerCooldown <= 0 {
+		policy.BreakerCooldown = 30 * time.Second
+	}
+	return &guard{policy: policy, limiter: newLimiter(policy.QPS)}
+}
+
+// Do 执行 fn，每次尝试前限速，瞬时错误按指数退避重试。
+// fn 每次调用都应重新构造请求，以便讯飞签名使用新的 Date。
+// nil guard 直接执行 fn。
+func (g *guard) Do(ctx context.Context, fn func() error) error {
+	if g == nil {
+		return fn()
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err := g.allow(); err != nil {
+			return err
+		}
+		if err := g.limiter.Wait(ctx); err != nil {
+			g.release()
+			return err
+		}
+
+		err = fn()
+		g.record(err)
+		if err == nil || !isTransient(err) || attempt >= g.policy.MaxRetries {
+			return err
+		}
+
+		timer := time.NewTimer(g.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// allow 检查熔断状态，冷却结束后只放行一个探测请求
+func (g *guard) allow() error {
+	if g.policy.BreakerThreshold <= 0 {
+		return nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.failures < g.policy.BreakerThreshold {
+		return nil
+	}
+	if time.Now().Before(g.openUntil) || g.probing {
+		return ErrCircuitOpen
+	}
+	g.probing = true
+	return nil
+}
+
+// release 放弃本次探测机会
+func (g *guard) release() {
+	g.mu.Lock()
+	g.probing = false
+	g.mu.Unlock()
+}
+
+// record 记录请求结果，仅瞬时错误计入熔断
+func (g *guard) record(err error) {
+	if g.policy.BreakerThreshold <= 0 {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.probing = false
+	if err == nil || !isTransient(err) {
+		g.failures = 0
+		return
+	}
+	g.failures++
+	if g.failures >= g.policy.BreakerThreshold {
+		g.openUntil = time.Now().Add(g.policy.BreakerCooldown)
+	}
+}
+
+// backoff 返回第 attempt 次重试前的等待时间（带随机抖动）
+func (g *guard) backoff(attempt int) time.Duration {
+	d := g.policy.BaseDelay << attempt
+	if d <= 0 || d > g.policy.MaxDelay {
+		d = g.policy.MaxDelay
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// isTransient 判断错误是否值得重试
+func isTransient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, ErrRateLimited) || errors.Is(err, ErrServer) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,52 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestGuardRetry(t *testing.T) {
+	g := newGuard(Policy{MaxRetries: 2, BaseDelay: time.Millisecond})
+	calls := 0
+	err := g.Do(context.Background(), func() error {
+		calls++
+		if calls < 3 {
+			return statusError("test", http.StatusServiceUnavailable, nil)
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("expected success after 3 calls, got %v after %d", err, calls)
+	}
+
+	calls = 0
+	err = g.Do(context.Background(), func() error {
+		calls++
+		return statusError("test", http.StatusUnauthorized, nil)
+	})
+	if !errors.Is(err, ErrAuth) || calls != 1 {
+		t.Fatalf("non-transient error must not be retried, got %v after %d calls", err, calls)
+	}
+}
+
+func TestGuardBreaker(t *testing.T) {
+	g := newGuard(Policy{BreakerThreshold: 2, BreakerCooldown: 20 * time.Millisecond})
+	fail := func() error { return statusError("test", http.StatusTooManyRequests, nil) }
+
+	_ = g.Do(context.Background(), fail)
+	_ = g.Do(context.Background(), fail)
+	if err := g.Do(context.Background(), fail); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected open circuit, got %v", err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if err := g.Do(context.Background(), func() error { return nil }); err != nil {
+		t.Fatalf("expected probe to pass after cooldown, got %v", err)
+	}
+	if err := g.Do(context.Background(), fail); errors.Is(err, ErrCircuitOpen) {
+		t.Fatal("circuit should be closed after a successful probe")
+	}
+}
@@ -0,0 +1,117 @@
+package translator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// defaultTimeout 各服务默认 HTTP 客户端的超时时间
+const defaultTimeout = 30 * time.Second
+
+// Request 单次翻译请求
+type Request struct {
+	Text string `json:"text"`
+	From string `json:"from,omitempty"` // 源语言，为空时使用实例默认值
+	To   string `json:"to,omitempty"`   // 目标语言，为空时使用实例默认值
+}
+
+// Provider 与具体翻译服务无关的翻译接口
+type Provider interface {
+	// Name 返回服务提供方名称，如 "xfyun"、"baidu"
+	Name() string
+	// TranslateContext 翻译单条文本
+	TranslateContext(ctx context.Context, req Request) (*TranslationResult, error)
+	// TranslateBatch 批量翻译，结果与 reqs 一一对应
+	TranslateBatch(ctx context.Context, reqs []Request) ([]*TranslationResult, error)
+}
+
+// translateEach 逐条调用 TranslateContext，供不支持原生批量接口的服务使用
+func translateEach(ctx context.Context, p Provider, reqs []Request) ([]*TranslationResult, error) {
+	results := make([]*TranslationResult, len(reqs))
+	for i, req := range reqs {
+		res, err := p.TranslateContext(ctx, req)
+		if err != nil {
+			return results, fmt.Errorf("translate item %d: %w", i, err)
+		}
+		results[i] = res
+	}
+	return results, nil
+}
+
+// translateChunks 将同一语言对的批量请求按条数与字节数上限切分后依次调用 fn，
+// 单条超过字节上限时单独成组，由服务端报告错误
+func translateChunks(reqs []Request, maxItems, maxBytes int, fn func(chunk []Request) ([]*TranslationResult, error)) ([]*TranslationResult, error) {
+	results := make([]*TranslationResult, 0, len(reqs))
+	for start := 0; start < len(reqs); {
+		end, size := start, 0
+		for end < len(reqs) && end-start < maxItems {
+			n := len(reqs[end].Text)
+			if end > start && size+n > maxBytes {
+				break
+			}
+			size += n
+			end++
+		}
+		chunk, err := fn(reqs[start:end])
+		if err != nil {
+			return results, fmt.Errorf("translate items %d-%d: %w", start, end-1, err)
+		}
+		results = append(results, chunk...)
+		start = end
+	}
+	return results, nil
+}
+
+// sameLanguages 判断批量请求是否使用同一语言对，可合并为一次原生批量调用
+func sameLanguages(reqs []Request) bool {
+	for _, req := range reqs[1:] {
+		if req.From != reqs[0].From || req.To != reqs[0].To {
+			return false
+		}
+	}
+	return true
+}
+
+// postJSON 发送 JSON 请求并解析 JSON 响应
+func postJSON(ctx context.Context, client *http.Client, provider, url string, header http.Header, in, out any) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("marshal json failed: %w", err)
+	}
+	request, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("create request failed: %w", err)
+	}
+	for k, v := range header {
+		request.Header[k] = v
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	return doJSON(client, request, provider, out)
+}
+
+// doJSON 发送请求并将响应体解析到 out，非 200 响应返回 *APIError
+func doJSON(client *http.Client, request *http.Request, provider string, out any) error {
+	response, err := client.Do(request)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("read response failed: %w", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return statusError(provider, response.StatusCode, body)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+package translator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ResourceOptions 资源文件翻译配置
+type ResourceOptions struct {
+	From      string
+	To        string
+	Overwrite bool // PO 文件中已有译文时是否重新翻译
+}
+
+// TranslateResourceFile 按扩展名翻译 JSON、YAML 或 PO 资源文件并写入 dst
+func TranslateResourceFile(ctx context.Context, p Provider, src, dst string, opts ResourceOptions) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("read resource file failed: %w", err)
+	}
+
+	var out []byte
+	switch ext := strings.ToLower(filepath.Ext(src)); ext {
+	case ".json":
+		out, err = TranslateJSON(ctx, p, data, opts)
+	case ".yaml", ".yml":
+		out, err = TranslateYAML(ctx, p, data, opts)
+	case ".po", ".pot":
+		out, err = TranslatePO(ctx, p, data, opts)
+	default:
+		return fmt.Errorf("unsupported resource format %q", ext)
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, out, 0o644)
+}
+
+// placeholderPattern 匹配 printf 风格占位符，如 %s、%d、%[1]s、%(name)s、%ld、%%。
+// 动词须为 printf 的合法动词且其后不紧跟字母或数字，避免 "50%off" 被视为占位符
+var placeholderPattern = regexp.MustCompile(`%%|%(?:\[\d+\]|\([A-Za-z_]\w*\))?[-+#0]*\d*(?:\.\d+)?(?:hh|h|ll|l|z|j|t|L)?[bcdeEfFgGiopqsStTuvxX]\b`)
+
+// messageTokenRe 匹配翻译前插入的占位符，容忍翻译服务在其中插入空白
+var messageTokenRe = regexp.MustCompile(`__\s*PH\s*(\d+)\s*__`)
+
+// icuPluralRe 匹配 ICU plural/select 参数头，如 "count, plural,"
+var icuPluralRe = regexp.MustCompile(`^\s*[\w.]+\s*,\s*(plural|select|selectordinal)\s*,`)
+
+// message 编译后的待翻译消息：units 为需要翻译的文本（占位符已替换为 __PH<n>__），
+// render 按 units 的顺序消费译文并还原消息结构。
+type message struct {
+	units  []string
+	render func(next func() string) string
+}
+
+// icuBranch ICU plural/select 中的一个分支，如 " one {# item}"
+type icuBranch struct {
+	key string // 含前导空白的分支键
+	msg *message
+}
+
+// compileMessage 将消息拆分为待翻译文本与占位符。
+// {name}、{{name}}、%s 等占位符原样保留；ICU plural/select 的各分支分别翻译；
+// inPlural 为 true 时 # 也视为占位符。
+func compileMessage(s string, inPlural bool) *message {
+	var (
+		template strings.Builder
+		holders  []func(next func() string) string
+	)
+	addHolder := func(render func(next func() string) string) {
+		fmt.Fprintf(&template, "__PH%d__", len(holders))
+		holders = append(holders, render)
+	}
+	literal := func(text string) func(func() string) string {
+		return func(func() string) string { return text }
+	}
+	addText := func(text string) {
+		last := 0
+		for _, loc := range placeholderPattern.FindAllStringIndex(text, -1) {
+			writeText(&template, text[last:loc[0]], inPlural, addHolder, literal)
+			addHolder(literal(text[loc[0]:loc[1]]))
+			last = loc[1]
+		}
+		writeText(&template, text[last:], inPlural, addHolder, literal)
+	}
+
+	var nested []*message
+	for s != "" {
+		open := strings.IndexByte(s, '{')
+		if open < 0 {
+			addText(s)
+			break
+		}
+		end := matchBrace(s, open)
+		if end < 0 {
+			addText(s)
+			break
+		}
+		addText(s[:open])
+		arg := s[open : end+1]
+		if header := icuPluralRe.FindString(arg[1 : len(arg)-1]); header != "" {
+			branches, ok := parseBranches(arg[1+len(header) : len(arg)-1])
+			if ok {
+				for _, b := range branches {
+					nested = append(nested, b.msg)
+				}
+				addHolder(func(next func() string) string {
+					var b strings.Builder
+					b.WriteString("{" + header)
+					for _, br := range branches {
+						b.WriteString(br.key + "{" + br.msg.render(next) + "}")
+					}
+					b.WriteString(trailingSpace(arg[1 : len(arg)-1]))
+					b.WriteString("}")
+					return b.String()
+				})
+				s = s[end+1:]
+				continue
+			}
+		}
+		addHolder(literal(arg))
+		s = s[end+1:]
+	}
+
+	text := template.String()
+	translatable := hasLetters(messageTokenRe.ReplaceAllString(text, ""))
+
+	m := &message{}
+	if translatable {
+		m.units = append(m.units, text)
+	}
+	for _, n := range nested {
+		m.units = append(m.units, n.units...)
+	}
+	m.render = func(next func() string) string {
+		out := text
+		if translatable {
+			out = next()
+		}
+		rendered := make([]string, len(holders))
+		for i, h := range holders {
+			rendered[i] = h(next)
+		}
+		return messageTokenRe.ReplaceAllStringFunc(out, func(tok string) string {
+			n, err := strconv.Atoi(messageTokenRe.FindStringSubmatch(tok)[1])
+			if err != nil || n >= len(rendered) {
+				return tok
+			}
+			return rendered[n]
+		})
+	}
+	return m
+}
+
+// writeText 写入普通文本，plural 分支中的 # 作为占位符
+func writeText(b *strings.Builder, text string, inPlural bool,
+	addHolder func(func(func() string) string), literal func(string) func(func() string) string) {
+	if !inPlural {
+		b.WriteString(text)
+		return
+	}
+	parts := strings.Split(text, "#")
+	for i, part := range parts {
+		if i > 0 {
+			addHolder(literal("#"))
+		}
+		b.WriteString(part)
+	}
+}
+
+// matchBrace 返回与 s[open] 处 '{' 匹配的 '}' 位置，不匹配时返回 -1
+func matchBrace(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// parseBranches 解析 ICU plural/select 的分支列表，如 " one {# item} other {# items}"
+func parseBranches(s string) ([]icuBranch, bool) {
+	var branches []icuBranch
+	for strings.TrimSpace(s) != "" {
+		open := strings.IndexByte(s, '{')
+		if open < 0 || strings.TrimSpace(s[:open]) == "" {
+			return nil, false
+		}
+		end := matchBrace(s, open)
+		if end < 0 {
+			return nil, false
+		}
+		branches = append(branches, icuBranch{
+			key: s[:open],
+			msg: compileMessage(s[open+1:end], true),
+		})
+		s = s[end+1:]
+	}
+	return branches, len(branches) > 0
+}
+
+func trailingSpace(s string) string {
+	return s[len(strings.TrimRightFunc(s, unicode.IsSpace)):]
+}
+
+func hasLetters(s string) bool {
+	return strings.IndexFunc(s, unicode.IsLetter) >= 0
+}
+
+// resourceBatch 收集资源文件中的消息，去重后一次批量翻译
+type resourceBatch struct {
+	messages []*message
+	index    map[string]int
+	units    []string
+}
+
+func newResourceBatch() *resourceBatch {
+	return &resourceBatch{index: make(map[string]int)}
+}
+
+// add 登记一条消息，返回其在 batch 中的序号
+func (b *resourceBatch) add(s string, inPlural bool) int {
+	m := compileMessage(s, inPlural)
+	for _, u := range m.units {
+		if _, ok := b.index[u]; !ok {
+			b.index[u] = len(b.units)
+			b.units = append(b.units, u)
+		}
+	}
+	b.messages = append(b.messages, m)
+	return len(b.messages) - 1
+}
+
+// translate 翻译所有消息，返回与 add 顺序一致的译文
+func (b *resourceBatch) translate(ctx context.Context, p Provider, opts ResourceOptions) ([]string, error) {
+	translated := make([]string, len(b.units))
+	if len(b.units) > 0 {
+		reqs := make([]Request, len(b.units))
+		for i, u := range b.units {
+			reqs[i] = Request{Text: u, From: opts.From, To: opts.To}
+		}
+		results, err := p.TranslateBatch(ctx, reqs)
+		if err != nil {
+			return nil, err
+		}
+		for i, res := range results {
+			translated[i] = res.Target
+		}
+	}
+
+	out := make([]string, len(b.messages))
+	for i, m := range b.messages {
+		units := m.units
+		out[i] = m.render(func() string {
+			u := units[0]
+			units = units[1:]
+			return translated[b.index[u]]
+		})
+	}
+	return out, nil
+}
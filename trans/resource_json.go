@@ -0,0 +1,91 @@
+package translator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// TranslateJSON 翻译 JSON 资源中的所有字符串值，键、格式与顺序保持不变
+func TranslateJSON(ctx context.Context, p Provider, data []byte, opts ResourceOptions) ([]byte, error) {
+	type span struct{ start, end int }
+
+	// 逐个 token 扫描，记录字符串值（非键）在原文中的位置
+	var (
+		spans  []span
+		values []string
+		stack  []bool // true 表示对象，false 表示数组
+		isKey  bool
+	)
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	prev := 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse json failed: %w", err)
+		}
+		cur := int(dec.InputOffset())
+
+		switch v := tok.(type) {
+		case json.Delim:
+			switch v {
+			case '{':
+				stack = append(stack, true)
+				isKey = true
+			case '[':
+				stack = append(stack, false)
+			case '}', ']':
+				stack = stack[:len(stack)-1]
+				isKey = len(stack) > 0 && stack[len(stack)-1]
+			}
+		case string:
+			if !isKey {
+				start := prev + bytes.IndexByte(data[prev:cur], '"')
+				spans = append(spans, span{start, cur})
+				values = append(values, v)
+			}
+			if len(stack) > 0 && stack[len(stack)-1] {
+				isKey = !isKey
+			}
+		default:
+			if len(stack) > 0 && stack[len(stack)-1] {
+				isKey = true
+			}
+		}
+		prev = cur
+	}
+
+	batch := newResourceBatch()
+	for _, v := range values {
+		batch.add(v, false)
+	}
+	translated, err := batch.translate(ctx, p, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	last := 0
+	for i, sp := range spans {
+		out.Write(data[last:sp.start])
+		out.Write(encodeJSONString(translated[i]))
+		last = sp.end
+	}
+	out.Write(data[last:])
+	return out.Bytes(), nil
+}
+
+// encodeJSONString 编码 JSON 字符串，不转义 HTML 字符
+func encodeJSONString(s string) []byte {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return bytes.TrimRight(b.Bytes(), "\n")
+}
@@ -0,0 +1,171 @@
+package translator
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// npluralsRe 从 PO 头部读取复数形式数量
+var npluralsRe = regexp.MustCompile(`nplurals\s*=\s*(\d+)`)
+
+// poEntry PO 文件中的一个条目，msgstr 行位于条目末尾
+type poEntry struct {
+	lines       []string
+	msgid       string
+	plural      string
+	hasPlural   bool
+	msgstr      []string
+	msgstrStart int
+}
+
+// TranslatePO 翻译 gettext PO 文件：为缺少译文的条目填写 msgstr，
+// 注释、msgctxt 与 msgid 原样保留。
+func TranslatePO(ctx context.Context, p Provider, data []byte, opts ResourceOptions) ([]byte, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	entries, err := parsePO(text)
+	if err != nil {
+		return nil, err
+	}
+
+	nplurals := 2
+	for _, e := range entries {
+		if e.msgid == "" && len(e.msgstr) > 0 {
+			if m := npluralsRe.FindStringSubmatch(e.msgstr[0]); m != nil {
+				nplurals, _ = strconv.Atoi(m[1])
+			}
+		}
+	}
+
+	type job struct {
+		entry  *poEntry
+		first  int // 单数形式在 batch 中的序号
+		plural int // 复数形式在 batch 中的序号
+	}
+	batch := newResourceBatch()
+	var jobs []job
+	for _, e := range entries {
+		if e.msgid == "" || e.msgstrStart < 0 || (!opts.Overwrite && !e.untranslated()) {
+			continue
+		}
+		j := job{entry: e, first: batch.add(e.msgid, false), plural: -1}
+		if e.hasPlural {
+			j.plural = batch.add(e.plural, false)
+		}
+		jobs = append(jobs, j)
+	}
+
+	translated, err := batch.translate(ctx, p, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, j := range jobs {
+		e := j.entry
+		lines := append([]string(nil), e.lines[:e.msgstrStart]...)
+		if !e.hasPlural {
+			lines = append(lines, formatPOString("msgstr", translated[j.first])...)
+		} else {
+			for n := 0; n < nplurals; n++ {
+				s := translated[j.plural]
+				if n == 0 && nplurals > 1 {
+					s = translated[j.first]
+				}
+				lines = append(lines, formatPOString(fmt.Sprintf("msgstr[%d]", n), s)...)
+			}
+		}
+		e.lines = lines
+	}
+
+	var out strings.Builder
+	for i, e := range entries {
+		if i > 0 {
+			out.WriteString("\n")
+		}
+		out.WriteString(strings.Join(e.lines, "\n"))
+		out.WriteString("\n")
+	}
+	return []byte(out.String()), nil
+}
+
+func (e *poEntry) untranslated() bool {
+	for _, s := range e.msgstr {
+		if s != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// parsePO 以空行分隔条目并解析各字段
+func parsePO(text string) ([]*poEntry, error) {
+	var entries []*poEntry
+	for _, block := range strings.Split(strings.TrimRight(text, "\n"), "\n\n") {
+		block = strings.Trim(block, "\n")
+		if block == "" {
+			continue
+		}
+		e := &poEntry{lines: strings.Split(block, "\n"), msgstrStart: -1}
+
+		var field *string
+		for i, line := range e.lines {
+			line = strings.TrimSpace(line)
+			switch {
+			case line == "" || strings.HasPrefix(line, "#"):
+				field = nil
+				continue
+			case strings.HasPrefix(line, `"`):
+				if field == nil {
+					return nil, fmt.Errorf("po: unexpected string continuation: %s", line)
+				}
+				s, err := strconv.Unquote(line)
+				if err != nil {
+					return nil, fmt.Errorf("po: invalid string %s: %w", line, err)
+				}
+				*field += s
+				continue
+			}
+
+			keyword, rest, _ := strings.Cut(line, " ")
+			s, err := strconv.Unquote(strings.TrimSpace(rest))
+			if err != nil {
+				return nil, fmt.Errorf("po: invalid string %s: %w", line, err)
+			}
+			switch {
+			case keyword == "msgid":
+				e.msgid = s
+				field = &e.msgid
+			case keyword == "msgid_plural":
+				e.plural, e.hasPlural = s, true
+				field = &e.plural
+			case keyword == "msgstr" || strings.HasPrefix(keyword, "msgstr["):
+				if e.msgstrStart < 0 {
+					e.msgstrStart = i
+				}
+				e.msgstr = append(e.msgstr, s)
+				field = &e.msgstr[len(e.msgstr)-1]
+			default: // msgctxt 等其它字段
+				var ignored string
+				field = &ignored
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// formatPOString 生成 PO 字段行，含换行的文本按 gettext 惯例拆为多行
+func formatPOString(keyword, s string) []string {
+	if !strings.Contains(strings.TrimSuffix(s, "\n"), "\n") {
+		return []string{keyword + " " + strconv.Quote(s)}
+	}
+	lines := []string{keyword + ` ""`}
+	for _, part := range strings.SplitAfter(s, "\n") {
+		if part != "" {
+			lines = append(lines, strconv.Quote(part))
+		}
+	}
+	return lines
+}
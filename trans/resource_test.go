@@ -0,0 +1,96 @@
+package translator
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestCompileMessage(t *testing.T) {
+	cases := map[string]string{
+		"Hello {name}, you have %d new messages":        "HELLO {name}, YOU HAVE %d NEW MESSAGES",
+		"{count, plural, one {# item} other {# items}}": "{count, plural, one {# ITEM} other {# ITEMS}}",
+		"Total: {{amount}} (%[1]s)":                     "TOTAL: {{amount}} (%[1]s)",
+		"{name}":                                        "{name}",
+		"50% off":                                       "50% OFF",
+		"50%off, %d%% done, %ld items":                  "50%OFF, %d%% DONE, %ld ITEMS",
+		"%5.2f kg, %v	%q":                               "%5.2f KG, %v	%q",
+	}
+	for in, want := range cases {
+		b := newResourceBatch()
+		b.add(in, false)
+		got, err := b.translate(context.Background(), upperProvider{}, ResourceOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got[0] != want {
+			t.Errorf("translate(%q) = %q, want %q", in, got[0], want)
+		}
+	}
+}
+
+func TestTranslateJSON(t *testing.T) {
+	in := `{
+  "greeting": "hello {name}",
+  "menu": {"items": ["open", "close"], "count": 2, "enabled": true},
+  "empty": ""
+}`
+	out, err := TranslateJSON(context.Background(), upperProvider{}, []byte(in), ResourceOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "greeting": "HELLO {name}",
+  "menu": {"items": ["OPEN", "CLOSE"], "count": 2, "enabled": true},
+  "empty": ""
+}`
+	if string(out) != want {
+		t.Fatalf("TranslateJSON() =\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestTranslateYAML(t *testing.T) {
+	in := "# greeting\ngreeting: hello {name}\nitems:\n  - open\n  - close\ncount: 2\n"
+	out, err := TranslateYAML(context.Background(), upperProvider{}, []byte(in), ResourceOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# greeting\ngreeting: HELLO {name}\nitems:\n  - OPEN\n  - CLOSE\ncount: 2\n"
+	if string(out) != want {
+		t.Fatalf("TranslateYAML() =\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestTranslatePO(t *testing.T) {
+	in := strings.Join([]string{
+		`msgid ""`,
+		`msgstr ""`,
+		`"Plural-Forms: nplurals=2; plural=(n != 1);\n"`,
+		``,
+		`#: main.go:10`,
+		`msgid "Hello %s"`,
+		`msgstr ""`,
+		``,
+		`msgid "done"`,
+		`msgstr "fertig"`,
+		``,
+		`msgid "one file"`,
+		`msgid_plural "%d files"`,
+		`msgstr[0] ""`,
+		`msgstr[1] ""`,
+		``,
+	}, "\n")
+	out, err := TranslatePO(context.Background(), upperProvider{}, []byte(in), ResourceOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"#: main.go:10\nmsgid \"Hello %s\"\nmsgstr \"HELLO %s\"",
+		"msgid \"done\"\nmsgstr \"fertig\"",
+		"msgstr[0] \"ONE FILE\"\nmsgstr[1] \"%d FILES\"",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
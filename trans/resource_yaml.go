@@ -0,0 +1,61 @@
+package translator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TranslateYAML 翻译 YAML 资源中的所有字符串值，键、注释与顺序保持不变
+func TranslateYAML(ctx context.Context, p Provider, data []byte, opts ResourceOptions) ([]byte, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("parse yaml failed: %w", err)
+	}
+
+	var nodes []*yaml.Node
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		switch n.Kind {
+		case yaml.DocumentNode, yaml.SequenceNode:
+			for _, c := range n.Content {
+				walk(c)
+			}
+		case yaml.MappingNode:
+			// Content 依次为键、值，仅翻译值
+			for i := 1; i < len(n.Content); i += 2 {
+				walk(n.Content[i])
+			}
+		case yaml.ScalarNode:
+			if n.Tag == "!!str" {
+				nodes = append(nodes, n)
+			}
+		}
+	}
+	walk(&root)
+
+	batch := newResourceBatch()
+	for _, n := range nodes {
+		batch.add(n.Value, false)
+	}
+	translated, err := batch.translate(ctx, p, opts)
+	if err != nil {
+		return nil, err
+	}
+	for i, n := range nodes {
+		n.Value = translated[i]
+	}
+
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(&root); err != nil {
+		return nil, fmt.Errorf("encode yaml failed: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encode yaml failed: %w", err)
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,25 @@
+package translator
+
+import "testing"
+
+// 期望值由独立实现（Python hmac/hashlib）计算
+func TestSignBody(t *testing.T) {
+	if got, want := signBody(`{"a":1}`), "AVq9f1zFei3ZS3WQ8ErYCEJzkF7jPsXOvq5iJ2qX+GI="; got != want {
+		t.Fatalf("signBody() = %q, want %q", got, want)
+	}
+}
+
+func TestGenerateSignature(t *testing.T) {
+	got := generateSignature(
+		"ntrans.xfyun.cn",
+		"Tue, 28 May 2019 09:10:42 GMT",
+		"POST",
+		"/v2/ots",
+		"HTTP/1.1",
+		"SHA-256=AVq9f1zFei3ZS3WQ8ErYCEJzkF7jPsXOvq5iJ2qX+GI=",
+		"secret",
+	)
+	if want := "mT1pzdjMAaPSkrJAD+MJmXlqm+cQgoBbErClZhGVPLw="; got != want {
+		t.Fatalf("generateSignature() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,131 @@
+package translator
+
+import (
+	"context"
+	"sync"
+)
+
+// Item 流式翻译的输入条目
+type Item struct {
+	ID string // 调用方用于关联结果的标识
+	Request
+}
+
+// Result 流式翻译的结果，Err 非空时 Result 为 nil
+type Result struct {
+	ID     string
+	Index  int // 条目在输入流中的序号，从 0 开始
+	Result *TranslationResult
+	Err    error
+}
+
+// Progress 流式翻译进度
+type Progress struct {
+	Done   int // 已完成条目数（含失败）
+	Failed int // 失败条目数
+}
+
+// StreamOptions 流式翻译配置
+type StreamOptions struct {
+	Concurrency int            // 并发数，默认 4
+	Unordered   bool           // 为 true 时按完成顺序输出，否则按输入顺序输出
+	OnProgress  func(Progress) // 每输出一个结果调用一次，调用是串行的
+}
+
+// TranslateStream 以 worker pool 并发翻译 items 中的条目，单条失败不影响其它条目。
+// items 关闭且所有结果输出后关闭返回的 channel；ctx 取消后不再读取新条目，
+// 已读取的条目以 ctx 错误返回。调用方需持续读取结果直至 channel 关闭。
+func TranslateStream(ctx context.Context, p Provider, items <-chan Item, opts StreamOptions) <-chan Result {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+
+	type job struct {
+		index int
+		item  Item
+	}
+	jobs := make(chan job)
+	done := make(chan Result, opts.Concurrency)
+	out := make(chan Result)
+	// 有序输出时限制已读取但未输出的条目数，避免慢条目导致缓冲无限增长
+	window := make(chan struct{}, opts.Concurrency*4)
+
+	go func() {
+		defer close(jobs)
+		for index := 0; ; index++ {
+			select {
+			case <-ctx.Done():
+				return
+			case window <- struct{}{}:
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case item, ok := <-items:
+				if !ok {
+					return
+				}
+				jobs <- job{index: index, item: item}
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for w := 0; w < opts.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				r := Result{ID: j.item.ID, Index: j.index}
+				if err := ctx.Err(); err != nil {
+					r.Err = err
+				} else {
+					r.Result, r.Err = p.TranslateContext(ctx, j.item.Request)
+				}
+				done <- r
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	go func() {
+		defer close(out)
+		var (
+			progress Progress
+			next     int
+			pending  = make(map[int]Result)
+		)
+		emit := func(r Result) {
+			out <- r
+			<-window
+			progress.Done++
+			if r.Err != nil {
+				progress.Failed++
+			}
+			if opts.OnProgress != nil {
+				opts.OnProgress(progress)
+			}
+		}
+		for r := range done {
+			if opts.Unordered {
+				emit(r)
+				continue
+			}
+			pending[r.Index] = r
+			for {
+				r, ok := pending[next]
+				if !ok {
+					break
+				}
+				delete(pending, next)
+				next++
+				emit(r)
+			}
+		}
+	}()
+
+	return out
+}
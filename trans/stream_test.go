@@ -0,0 +1,80 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"testing"
+	"time"
+)
+
+type flakyProvider struct{ upperProvider }
+
+func (p flakyProvider) TranslateContext(ctx context.Context, req Request) (*TranslationResult, error) {
+	time.Sleep(time.Duration(rand.IntN(3)) * time.Millisecond)
+	if strings.HasPrefix(req.Text, "bad") {
+		return nil, errors.New("bad input")
+	}
+	return p.upperProvider.TranslateContext(ctx, req)
+}
+
+func TestTranslateStream(t *testing.T) {
+	items := make(chan Item)
+	go func() {
+		defer close(items)
+		for i := 0; i < 100; i++ {
+			text := fmt.Sprintf("item %d", i)
+			if i%10 == 0 {
+				text = "bad " + text
+			}
+			items <- Item{ID: fmt.Sprint(i), Request: Request{Text: text}}
+		}
+	}()
+
+	var last Progress
+	results := TranslateStream(context.Background(), flakyProvider{}, items, StreamOptions{
+		Concurrency: 8,
+		OnProgress:  func(p Progress) { last = p },
+	})
+
+	n := 0
+	for r := range results {
+		if r.Index != n || r.ID != fmt.Sprint(n) {
+			t.Fatalf("result %d out of order: %+v", n, r)
+		}
+		if n%10 == 0 {
+			if r.Err == nil {
+				t.Fatalf("expected error for item %d", n)
+			}
+		} else if r.Err != nil || r.Result.Target != fmt.Sprintf("ITEM %d", n) {
+			t.Fatalf("unexpected result for item %d: %+v", n, r)
+		}
+		n++
+	}
+	if n != 100 || last.Done != 100 || last.Failed != 10 {
+		t.Fatalf("got %d results, progress %+v", n, last)
+	}
+}
+
+func TestTranslateStreamCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	items := make(chan Item) // 永不关闭
+	results := TranslateStream(ctx, upperProvider{}, items, StreamOptions{Unordered: true})
+
+	items <- Item{ID: "a", Request: Request{Text: "a"}}
+	if r := <-results; r.Err != nil || r.ID != "a" {
+		t.Fatalf("unexpected result: %+v", r)
+	}
+	cancel()
+
+	select {
+	case _, ok := <-results:
+		if ok {
+			t.Fatal("expected results channel to close after cancel")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("results channel not closed after cancel")
+	}
+}
@@ -2,18 +2,23 @@ package translator
 
 import (
 	"bytes"
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
+	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 )
 
-// Translator 翻译接口
+// Translator 讯飞（xfyun）机器翻译接口，在 Provider 的基础上保留原始响应相关方法
 type Translator interface {
+	Provider
 	Translate(text string) (string, error)
 	TranslateWithResult(text string) (*TranslationResult, error)
 	Extract(result string) (*TranslationResult, error)
@@ -25,6 +30,10 @@ type Option func(*translator)
 // translator 实现 Translator 接口
 type translator struct {
 	client    *http.Client
+	guard     *guard
+	proxy     *url.URL
+	timeout   time.Duration
+	scheme    string
 	host      string
 	uri       string
 	appid     string
@@ -37,26 +46,34 @@ type translator struct {
 
 // Config 配置结构体（用于结构体方式初始化）
 type Config struct {
-	Host      string
-	URI       string
-	AppID     string
-	Secret    string
-	APIKey    string
-	FromLang  string
-	ToLang    string
-	HTTPProto string
+	Scheme     string // 默认 https
+	Host       string
+	URI        string
+	AppID      string
+	Secret     string
+	APIKey     string
+	FromLang   string
+	ToLang     string
+	HTTPProto  string
+	HTTPClient *http.Client  // 自定义客户端，设置后忽略 Proxy 与 Timeout
+	Policy     *Policy       // 限速、重试与熔断策略，为空时不启用
+	Proxy      *url.URL      // 为空时读取 HTTP(S)_PROXY 环境变量
+	Timeout    time.Duration // 默认 30s
 }
 
 // TranslationResult 翻译结果结构体
 type TranslationResult struct {
-	Source string `json:"src"` // 源文本
-	Target string `json:"dst"` // 翻译结果
+	Source string `json:"src"`            // 源文本
+	Target string `json:"dst"`            // 翻译结果
+	From   string `json:"from,omitempty"` // 源语言
+	To     string `json:"to,omitempty"`   // 目标语言
 }
 
 // New 创建翻译实例（支持 Option 方式）
 func New(options ...Option) Translator {
 	t := &translator{
-		client:    &http.Client{},
+		timeout:   defaultTimeout,
+		scheme:    "https",
 		host:      "ntrans.xfyun.cn",
 		uri:       "/v2/ots",
 		appid:     "**",
@@ -70,14 +87,18 @@ func New(options ...Option) Translator {
 	for _, opt := range options {
 		opt(t)
 	}
+	if t.client == nil {
+		t.client = newHTTPClient(t.proxy, t.timeout)
+	}
 
 	return t
 }
 
 // NewWithConfig 创建翻译实例（支持结构体方式）
 func NewWithConfig(config Config) Translator {
-	return &translator{
-		client:    &http.Client{},
+	t := &translator{
+		client:    config.HTTPClient,
+		scheme:    config.Scheme,
 		host:      config.Host,
 		uri:       config.URI,
 		appid:     config.AppID,
@@ -87,6 +108,69 @@ func NewWithConfig(config Config) Translator {
 		toLang:    config.ToLang,
 		httpProto: config.HTTPProto,
 	}
+	if t.scheme == "" {
+		t.scheme = "https"
+	}
+	if config.Policy != nil {
+		t.guard = newGuard(*config.Policy)
+	}
+	if t.client == nil {
+		timeout := config.Timeout
+		if timeout == 0 {
+			timeout = defaultTimeout
+		}
+		t.client = newHTTPClient(config.Proxy, timeout)
+	}
+	return t
+}
+
+// newHTTPClient 创建默认 HTTP 客户端。
+// 签名中的请求行固定为 httpProto（默认 HTTP/1.1），因此禁用 HTTP/2 协商，
+// 保证服务端看到的请求行与签名一致。
+func newHTTPClient(proxy *url.URL, timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ForceAttemptHTTP2 = false
+	transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	if proxy != nil {
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	return &http.Client{Transport: transport, Timeout: timeout}
+}
+
+// WithScheme 设置请求协议，默认 https
+func WithScheme(scheme string) Option {
+	return func(t *translator) {
+		t.scheme = scheme
+	}
+}
+
+// WithHTTPClient 使用自定义 HTTP 客户端，设置后 WithProxy 与 WithTimeout 不再生效。
+// 自定义 Transport 需使用与 WithHTTPProto 一致的协议版本，否则签名校验会失败。
+func WithHTTPClient(client *http.Client) Option {
+	return func(t *translator) {
+		t.client = client
+	}
+}
+
+// WithProxy 设置代理地址，默认读取 HTTP(S)_PROXY 环境变量
+func WithProxy(proxy *url.URL) Option {
+	return func(t *translator) {
+		t.proxy = proxy
+	}
+}
+
+// WithPolicy 设置限速、重试与熔断策略，重试时会重新生成 Date 与签名
+func WithPolicy(policy Policy) Option {
+	return func(t *translator) {
+		t.guard = newGuard(policy)
+	}
+}
+
+// WithTimeout 设置请求超时时间，默认 30s
+func WithTimeout(timeout time.Duration) Option {
+	return func(t *translator) {
+		t.timeout = timeout
+	}
 }
 
 // WithAppID 设置 AppID
@@ -145,16 +229,85 @@ func WithHTTPProto(proto string) Option {
 	}
 }
 
-// Translate 翻译文本
+// Name 返回服务提供方名称
+func (t *translator) Name() string {
+	return "xfyun"
+}
+
+// Translate 使用默认语言翻译文本，返回原始响应。
+// 服务端返回错误码时返回 *APIError。
 func (t *translator) Translate(text string) (string, error) {
-	data := []byte(text)
+	body, err := t.do(context.Background(), Request{Text: text})
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// TranslateContext 翻译单条文本，req 中未指定的语言使用实例默认值
+func (t *translator) TranslateContext(ctx context.Context, req Request) (*TranslationResult, error) {
+	req = t.withDefaults(req)
+	body, err := t.do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	res, err := t.Extract(string(body))
+	if err != nil {
+		return nil, err
+	}
+	if res.From == "" {
+		res.From = req.From
+	}
+	if res.To == "" {
+		res.To = req.To
+	}
+	return res, nil
+}
+
+// TranslateBatch 逐条翻译，结果与 reqs 一一对应
+func (t *translator) TranslateBatch(ctx context.Context, reqs []Request) ([]*TranslationResult, error) {
+	return translateEach(ctx, t, reqs)
+}
+
+func (t *translator) withDefaults(req Request) Request {
+	if req.From == "" {
+		req.From = t.fromLang
+	}
+	if req.To == "" {
+		req.To = t.toLang
+	}
+	return req
+}
+
+// do 按策略发送请求，返回原始响应体
+func (t *translator) do(ctx context.Context, req Request) ([]byte, error) {
+	var body []byte
+	err := t.guard.Do(ctx, func() error {
+		var err error
+		body, err = t.send(ctx, req)
+		return err
+	})
+	return body, err
+}
+
+// send 对请求签名并发送一次，返回原始响应体
+func (t *translator) send(ctx context.Context, req Request) ([]byte, error) {
+	req = t.withDefaults(req)
+	data := []byte(req.Text)
+	if len(data) > MaxTextBytes {
+		return nil, &APIError{
+			Provider: t.Name(),
+			Message:  fmt.Sprintf("text is %d bytes, limit is %d", len(data), MaxTextBytes),
+			Kind:     ErrTextTooLong,
+		}
+	}
 	param := map[string]any{
 		"common": map[string]any{
 			"app_id": t.appid,
 		},
 		"business": map[string]any{
-			"from": t.fromLang,
-			"to":   t.toLang,
+			"from": req.From,
+			"to":   req.To,
 		},
 		"data": map[string]any{
 			"text": base64.StdEncoding.EncodeToString(data),
@@ -163,7 +316,7 @@ func (t *translator) Translate(text string) (string, error) {
 
 	jsonData, err := json.Marshal(param)
 	if err != nil {
-		return "", fmt.Errorf("marshal json failed: %w", err)
+		return nil, fmt.Errorf("marshal json failed: %w", err)
 	}
 
 	requestBody := bytes.NewBuffer(jsonData)
@@ -184,9 +337,9 @@ func (t *translator) Translate(text string) (string, error) {
 	authHeader := fmt.Sprintf(`api_key="%s", algorithm="%s", headers="host date request-line digest", signature="%s"`,
 		t.apiKey, "hmac-sha256", signature)
 
-	request, err := http.NewRequest("POST", "http://"+t.host+t.uri, requestBody)
+	request, err := http.NewRequestWithContext(ctx, "POST", t.scheme+"://"+t.host+t.uri, requestBody)
 	if err != nil {
-		return "", fmt.Errorf("create request failed: %w", err)
+		return nil, fmt.Errorf("create request failed: %w", err)
 	}
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("Host", t.host)
@@ -197,46 +350,92 @@ func (t *translator) Translate(text string) (string, error) {
 
 	response, err := t.client.Do(request)
 	if err != nil {
-		return "", fmt.Errorf("request failed: %w", err)
+		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer response.Body.Close()
 
 	body, err := io.ReadAll(response.Body)
 	if err != nil {
-		return "", fmt.Errorf("read response failed: %w", err)
+		return nil, fmt.Errorf("read response failed: %w", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, statusError(t.Name(), response.StatusCode, body)
+	}
+	// 业务错误码同样作为错误返回，以便按类别重试
+	if resp, err := ParseResponse(body); err == nil {
+		if err := resp.Err(); err != nil {
+			return nil, err
+		}
 	}
 
-	return string(body), nil
+	return body, nil
+}
+
+// MaxTextBytes 讯飞 OTS 单次请求的原文字节上限
+const MaxTextBytes = 5000
+
+// Response 讯飞 OTS 完整响应
+type Response struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	SID     string `json:"sid"`
+	Data    struct {
+		Result struct {
+			From        string `json:"from"`
+			To          string `json:"to"`
+			TransResult struct {
+				Src string `json:"src"`
+				Dst string `json:"dst"`
+			} `json:"trans_result"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// ParseResponse 解析讯飞 OTS 响应
+func ParseResponse(body []byte) (*Response, error) {
+	var resp Response
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return &resp, nil
+}
+
+// Err 在响应码非 0 时返回 *APIError
+func (r *Response) Err() error {
+	if r.Code == 0 {
+		return nil
+	}
+	return &APIError{
+		Provider:   "xfyun",
+		HTTPStatus: http.StatusOK,
+		Code:       strconv.Itoa(r.Code),
+		Message:    r.Message,
+		SID:        r.SID,
+		Kind:       xfyunErrorKinds[r.Code],
+	}
 }
 
 // Extract 从API响应中提取翻译结果
 func (t *translator) Extract(result string) (*TranslationResult, error) {
-	// 定义完整的响应结构体
-	type FullResponse struct {
-		Data struct {
-			Result struct {
-				TransResult struct {
-					Src string `json:"src"`
-					Dst string `json:"dst"`
-				} `json:"trans_result"`
-			} `json:"result"`
-		} `json:"data"`
-	}
-
-	var fullResponse FullResponse
-	if err := json.Unmarshal([]byte(result), &fullResponse); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	resp, err := ParseResponse([]byte(result))
+	if err != nil {
+		return nil, err
+	}
+	if err := resp.Err(); err != nil {
+		return nil, err
 	}
 
 	// 检查是否有翻译结果
-	if fullResponse.Data.Result.TransResult.Dst == "" {
-		return nil, fmt.Errorf("no translation result found")
+	transResult := resp.Data.Result.TransResult
+	if transResult.Dst == "" {
+		return nil, fmt.Errorf("no translation result found (sid %s)", resp.SID)
 	}
 
-	transResult := fullResponse.Data.Result.TransResult
 	return &TranslationResult{
 		Source: transResult.Src,
 		Target: transResult.Dst,
+		From:   resp.Data.Result.From,
+		To:     resp.Data.Result.To,
 	}, nil
 }
 
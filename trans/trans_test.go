@@ -0,0 +1,142 @@
+package translator_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	translator "github.com/package-register/go-toolkit/trans"
+	"github.com/package-register/go-toolkit/trans/transtest"
+)
+
+func TestTranslateContext(t *testing.T) {
+	srv := transtest.NewServer()
+	defer srv.Close()
+	tr := translator.New(srv.Options()...)
+
+	res, err := tr.TranslateContext(context.Background(), translator.Request{Text: "你好", From: "cn", To: "ja"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Source != "你好" || res.Target != "ja:你好" || res.From != "cn" || res.To != "ja" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	// 默认语言对
+	res, err = tr.TranslateWithResult("世界")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Target != "en:世界" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if reqs := srv.Requests(); len(reqs) != 2 || reqs[1].From != "cn" || reqs[1].To != "en" {
+		t.Fatalf("unexpected requests: %+v", reqs)
+	}
+}
+
+func TestTranslateBadCredentials(t *testing.T) {
+	srv := transtest.NewServer()
+	defer srv.Close()
+	tr := translator.New(append(srv.Options(), translator.WithSecret("wrong"))...)
+
+	_, err := tr.TranslateContext(context.Background(), translator.Request{Text: "你好"})
+	var apiErr *translator.APIError
+	if !errors.As(err, &apiErr) || !errors.Is(err, translator.ErrAuth) {
+		t.Fatalf("expected auth APIError, got %v", err)
+	}
+	if apiErr.HTTPStatus != http.StatusUnauthorized || !strings.Contains(apiErr.Message, "signature") {
+		t.Fatalf("unexpected error: %+v", apiErr)
+	}
+	if len(srv.Requests()) != 0 {
+		t.Fatal("request with bad signature must be rejected")
+	}
+}
+
+func TestTranslateErrors(t *testing.T) {
+	srv := transtest.NewServer()
+	defer srv.Close()
+	tr := translator.New(srv.Options()...)
+	ctx := context.Background()
+
+	cases := []struct {
+		reply transtest.Reply
+		kind  error
+	}{
+		{transtest.Reply{Code: 11201, Message: "licc limit"}, translator.ErrQuotaExceeded},
+		{transtest.Reply{Code: 10107, Message: "illegal param"}, translator.ErrUnsupportedLanguage},
+		{transtest.Reply{Code: 10105, Message: "illegal access"}, translator.ErrAuth},
+		{transtest.Reply{Status: http.StatusTooManyRequests, Message: "too many requests"}, translator.ErrRateLimited},
+		{transtest.Reply{Status: http.StatusBadGateway, Message: "bad gateway"}, translator.ErrServer},
+	}
+	for _, c := range cases {
+		srv.Enqueue(c.reply)
+		_, err := tr.TranslateContext(ctx, translator.Request{Text: "你好"})
+		if !errors.Is(err, c.kind) {
+			t.Errorf("reply %+v: expected %v, got %v", c.reply, c.kind, err)
+		}
+		var apiErr *translator.APIError
+		if errors.As(err, &apiErr) && c.reply.Code != 0 && apiErr.SID == "" {
+			t.Errorf("reply %+v: missing sid", c.reply)
+		}
+	}
+
+	// 超长文本在本地拒绝，不发送请求
+	before := len(srv.Requests())
+	_, err := tr.TranslateContext(ctx, translator.Request{Text: strings.Repeat("长", translator.MaxTextBytes)})
+	if !errors.Is(err, translator.ErrTextTooLong) || len(srv.Requests()) != before {
+		t.Fatalf("expected local ErrTextTooLong, got %v", err)
+	}
+
+	// 旧接口在业务错误时同样返回错误
+	srv.Enqueue(transtest.Reply{Code: 10700, Message: "engine error"})
+	if _, err := tr.Translate("你好"); !errors.Is(err, translator.ErrServer) {
+		t.Fatalf("expected ErrServer from Translate, got %v", err)
+	}
+}
+
+func TestTranslateRetryResigns(t *testing.T) {
+	srv := transtest.NewServer()
+	defer srv.Close()
+	tr := translator.New(append(srv.Options(), translator.WithPolicy(translator.Policy{
+		MaxRetries: 2,
+		BaseDelay:  time.Millisecond,
+	}))...)
+
+	srv.Enqueue(transtest.Reply{Status: http.StatusServiceUnavailable}, transtest.Reply{Code: 10700})
+	res, err := tr.TranslateContext(context.Background(), translator.Request{Text: "你好"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Target != "en:你好" || len(srv.Requests()) != 3 {
+		t.Fatalf("expected success on third attempt, got %+v after %d requests", res, len(srv.Requests()))
+	}
+}
+
+func TestExtract(t *testing.T) {
+	tr := translator.New()
+
+	res, err := tr.Extract(`{"code":0,"sid":"its1","data":{"result":{"from":"cn","to":"en","trans_result":{"src":"你好","dst":"Hello"}}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Target != "Hello" || res.From != "cn" || res.To != "en" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	_, err = tr.Extract(`{"code":10313,"message":"appid mismatch","sid":"its2"}`)
+	var apiErr *translator.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "10313" || apiErr.SID != "its2" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := tr.Extract(`{"code":0,"data":{}}`); err == nil {
+		t.Fatal("expected error for empty result")
+	}
+	if _, err := tr.Extract(`not json`); err == nil {
+		t.Fatal("expected error for invalid json")
+	}
+}
@@ -0,0 +1,226 @@
+// Package transtest 提供用于测试的讯飞 OTS 模拟服务。
+//
+// Server 按讯飞的规则校验 Digest、Date 与 Authorization 请求头，
+// 校验通过后返回预设响应，无需访问网络即可测试签名与错误处理。
+package transtest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sync"
+	"time"
+
+	translator "github.com/package-register/go-toolkit/trans"
+)
+
+const (
+	AppID  = "test-appid"
+	APIKey = "test-api-key"
+	Secret = "test-api-secret"
+
+	// MaxClockSkew Date 请求头允许的最大时钟偏差，与讯飞一致
+	MaxClockSkew = 300 * time.Second
+)
+
+// authRe 解析 Authorization 请求头
+var authRe = regexp.MustCompile(`^api_key="([^"]*)", algorithm="([^"]*)", headers="([^"]*)", signature="([^"]*)"$`)
+
+// Reply 预设响应。Status 非 200 时按网关错误返回 {"message": ...}，
+// 否则返回 code/message 业务错误。
+type Reply struct {
+	Status  int
+	Code    int
+	Message string
+}
+
+// Request 服务端收到并校验通过的请求
+type Request struct {
+	From string
+	To   string
+	Text string
+	Date time.Time
+}
+
+// Server 讯飞 OTS 模拟服务
+type Server struct {
+	*httptest.Server
+
+	// Translate 生成译文，默认返回 "<to>:<text>"
+	Translate func(from, to, text string) string
+
+	mu       sync.Mutex
+	replies  []Reply
+	requests []Request
+}
+
+// NewServer 启动模拟服务，调用方负责 Close
+func NewServer() *Server {
+	s := &Server{
+		Translate: func(from, to, text string) string { return to + ":" + text },
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Enqueue 追加预设响应，按顺序用于后续请求，耗尽后恢复正常翻译
+func (s *Server) Enqueue(replies ...Reply) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replies = append(s.replies, replies...)
+}
+
+// Requests 返回校验通过的请求
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Options 返回指向模拟服务的 translator 配置
+func (s *Server) Options() []translator.Option {
+	u, _ := url.Parse(s.URL)
+	return []translator.Option{
+		translator.WithScheme(u.Scheme),
+		translator.WithHost(u.Host),
+		translator.WithAppID(AppID),
+		translator.WithAPIKey(APIKey),
+		translator.WithSecret(Secret),
+	}
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"message": err.Error()})
+		return
+	}
+
+	date, err := s.verify(r, body)
+	if err != nil {
+		status := http.StatusUnauthorized
+		if err == errDate {
+			status = http.StatusForbidden
+		}
+		writeJSON(w, status, map[string]any{"message": err.Error()})
+		return
+	}
+
+	var param struct {
+		Common struct {
+			AppID string `json:"app_id"`
+		} `json:"common"`
+		Business struct {
+			From string `json:"from"`
+			To   string `json:"to"`
+		} `json:"business"`
+		Data struct {
+			Text string `json:"text"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &param); err != nil {
+		writeJSON(w, http.StatusOK, map[string]any{"code": 10106, "message": "invalid json"})
+		return
+	}
+	if param.Common.AppID != AppID {
+		writeJSON(w, http.StatusOK, map[string]any{"code": 10313, "message": "appid and apikey do not match"})
+		return
+	}
+	text, err := base64.StdEncoding.DecodeString(param.Data.Text)
+	if err != nil {
+		writeJSON(w, http.StatusOK, map[string]any{"code": 10107, "message": "invalid data.text"})
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{From: param.Business.From, To: param.Business.To, Text: string(text), Date: date})
+	var reply *Reply
+	if len(s.replies) > 0 {
+		reply = &s.replies[0]
+		s.replies = s.replies[1:]
+	}
+	s.mu.Unlock()
+
+	sid := fmt.Sprintf("its%016x", time.Now().UnixNano())
+	if reply != nil {
+		if reply.Status != 0 && reply.Status != http.StatusOK {
+			writeJSON(w, reply.Status, map[string]any{"message": reply.Message})
+			return
+		}
+		if reply.Code != 0 {
+			writeJSON(w, http.StatusOK, map[string]any{"code": reply.Code, "message": reply.Message, "sid": sid})
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"code":    0,
+		"message": "success",
+		"sid":     sid,
+		"data": map[string]any{
+			"result": map[string]any{
+				"from": param.Business.From,
+				"to":   param.Business.To,
+				"trans_result": map[string]any{
+					"src": string(text),
+					"dst": s.Translate(param.Business.From, param.Business.To, string(text)),
+				},
+			},
+		},
+	})
+}
+
+var errDate = fmt.Errorf("HMAC signature cannot be verified, a valid date or x-date header is required for HMAC Authentication")
+
+// verify 按讯飞规则校验请求头，返回请求的 Date
+func (s *Server) verify(r *http.Request, body []byte) (time.Time, error) {
+	date, err := http.ParseTime(r.Header.Get("Date"))
+	if err != nil {
+		// 讯飞同样接受 time.RFC1123 格式的 UTC 时间
+		date, err = time.Parse(time.RFC1123, r.Header.Get("Date"))
+	}
+	if err != nil || time.Since(date).Abs() > MaxClockSkew {
+		return time.Time{}, errDate
+	}
+
+	sum := sha256.Sum256(body)
+	if r.Header.Get("Digest") != "SHA-256="+base64.StdEncoding.EncodeToString(sum[:]) {
+		return time.Time{}, fmt.Errorf("HMAC body digest does not match")
+	}
+
+	m := authRe.FindStringSubmatch(r.Header.Get("Authorization"))
+	if m == nil {
+		return time.Time{}, fmt.Errorf("HMAC authorization header is malformed")
+	}
+	if m[1] != APIKey {
+		return time.Time{}, fmt.Errorf("HMAC api_key is invalid")
+	}
+	if m[2] != "hmac-sha256" || m[3] != "host date request-line digest" {
+		return time.Time{}, fmt.Errorf("HMAC algorithm or headers not supported")
+	}
+
+	// 请求行取服务端实际看到的内容
+	signed := "host: " + r.Host + "\n" +
+		"date: " + r.Header.Get("Date") + "\n" +
+		r.Method + " " + r.RequestURI + " " + r.Proto + "\n" +
+		"digest: " + r.Header.Get("Digest")
+	mac := hmac.New(sha256.New, []byte(Secret))
+	mac.Write([]byte(signed))
+	if !hmac.Equal([]byte(m[4]), []byte(base64.StdEncoding.EncodeToString(mac.Sum(nil)))) {
+		return time.Time{}, fmt.Errorf("HMAC signature does not match")
+	}
+	return date, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}